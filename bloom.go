@@ -0,0 +1,93 @@
+package utils
+
+import (
+	"hash/maphash"
+	"math"
+)
+
+// BloomFilter is an approximate set of strings or byte slices using fixed memory
+// Testing for a value that was added always returns true, but testing for one
+// that wasn't may return true as well, at the false positive rate the filter
+// was sized for. A BloomFilter is not safe for concurrent use.
+type BloomFilter struct {
+	bits         []uint64
+	m            uint64 // The number of bits
+	k            int    // The number of hashes per value
+	seed1, seed2 maphash.Seed
+}
+
+// NewBloomFilter returns a filter sized for n values at the given false
+// positive rate, e.g. 0.01 for 1%
+// Rates outside (0, 1) use 0.01.
+func NewBloomFilter(n int, falsePositiveRate float64) *BloomFilter {
+	if n < 1 {
+		n = 1
+	}
+	if falsePositiveRate <= 0 || falsePositiveRate >= 1 {
+		falsePositiveRate = 0.01
+	}
+
+	// The optimal number of bits and hashes, see
+	// https://en.wikipedia.org/wiki/Bloom_filter#Optimal_number_of_hash_functions
+	m := uint64(math.Ceil(-float64(n) * math.Log(falsePositiveRate) / (math.Ln2 * math.Ln2)))
+	m = max(m, 64)
+	k := max(int(math.Round(float64(m)/float64(n)*math.Ln2)), 1)
+
+	return &BloomFilter{
+		bits:  make([]uint64, (m+63)/64),
+		m:     m,
+		k:     k,
+		seed1: maphash.MakeSeed(),
+		seed2: maphash.MakeSeed(),
+	}
+}
+
+// Add adds data to the filter
+func (f *BloomFilter) Add(data []byte) {
+	f.add(maphash.Bytes(f.seed1, data), maphash.Bytes(f.seed2, data))
+}
+
+// AddString adds str to the filter
+func (f *BloomFilter) AddString(str string) {
+	f.add(maphash.String(f.seed1, str), maphash.String(f.seed2, str))
+}
+
+// Test reports whether data was probably added
+func (f *BloomFilter) Test(data []byte) bool {
+	return f.test(maphash.Bytes(f.seed1, data), maphash.Bytes(f.seed2, data))
+}
+
+// TestString reports whether str was probably added
+func (f *BloomFilter) TestString(str string) bool {
+	return f.test(maphash.String(f.seed1, str), maphash.String(f.seed2, str))
+}
+
+// TestAndAddString reports whether str was probably added, and adds it
+func (f *BloomFilter) TestAndAddString(str string) bool {
+	h1, h2 := maphash.String(f.seed1, str), maphash.String(f.seed2, str)
+	found := f.test(h1, h2)
+	if !found {
+		f.add(h1, h2)
+	}
+	return found
+}
+
+// The k bit positions are derived from two hashes, h1 + i*h2, which is as
+// good as k independent hashes
+
+func (f *BloomFilter) add(h1, h2 uint64) {
+	for i := 0; i < f.k; i++ {
+		bit := (h1 + uint64(i)*h2) % f.m
+		f.bits[bit/64] |= 1 << (bit % 64)
+	}
+}
+
+func (f *BloomFilter) test(h1, h2 uint64) bool {
+	for i := 0; i < f.k; i++ {
+		bit := (h1 + uint64(i)*h2) % f.m
+		if f.bits[bit/64]&(1<<(bit%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
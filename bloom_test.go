@@ -0,0 +1,56 @@
+package utils
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestBloomFilter(t *testing.T) {
+	filter := NewBloomFilter(1000, 0.01)
+	for i := 0; i < 1000; i++ {
+		filter.AddString(strconv.Itoa(i))
+	}
+	filter.Add([]byte("bytes"))
+
+	// No false negatives
+	for i := 0; i < 1000; i++ {
+		if !filter.TestString(strconv.Itoa(i)) {
+			t.Fatalf("%d was added but not found", i)
+		}
+	}
+	if !filter.Test([]byte("bytes")) || !filter.TestString("bytes") {
+		t.Error("bytes was added but not found")
+	}
+
+	falsePositives := 0
+	for i := 1000; i < 11000; i++ {
+		if filter.TestString(strconv.Itoa(i)) {
+			falsePositives++
+		}
+	}
+	if rate := float64(falsePositives) / 10000; rate > 0.02 {
+		t.Errorf("got a false positive rate of %v, expected about 0.01", rate)
+	}
+}
+
+func TestBloomFilterTestAndAdd(t *testing.T) {
+	filter := NewBloomFilter(0, 2)
+	if filter.TestAndAddString("a") {
+		t.Error("a was found before it was added")
+	}
+	if !filter.TestAndAddString("a") {
+		t.Error("a was not found after it was added")
+	}
+}
+
+func BenchmarkBloomFilterTestAndAdd(b *testing.B) {
+	filter := NewBloomFilter(b.N, 0.01)
+	strs := make([]string, 1000)
+	for i := range strs {
+		strs[i] = strconv.Itoa(i)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		filter.TestAndAddString(strs[i%len(strs)])
+	}
+}
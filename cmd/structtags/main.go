@@ -0,0 +1,202 @@
+/*
+Command structtags adds, sets, removes and sorts struct tags in Go source files.
+
+Fields are selected with one of -struct, -line, -offset or -all, and the
+operations are applied in the order remove, set, add, auto and sort. Only the
+tags themselves are rewritten, so formatting and comments are preserved.
+
+-add and -set take one key:value each and can be repeated. The value is used
+as it is, so it can contain commas.
+
+Examples:
+
+	structtags -file user.go -struct User -auto json:camelcase,db:snakecase -sort
+	structtags -file user.go -line 10,20 -add validate:required
+	structtags -file user.go -struct User -add json:id,omitempty -add db:id
+	structtags -file user.go -offset 512 -remove xml,yaml -diff
+*/
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/nosco/go-utils"
+)
+
+func main() {
+	var (
+		file      = flag.String("file", "", "Go source `file` to rewrite")
+		structArg = flag.String("struct", "", "select all fields of the struct with this `name`")
+		lineArg   = flag.String("line", "", "select the fields on these `lines`, e.g. 10,20 or 12")
+		offsetArg = flag.Int("offset", -1, "select the fields of the struct at this byte `offset`")
+		allArg    = flag.Bool("all", false, "select the fields of all structs")
+		removeArg = flag.String("remove", "", "remove the tags with these `keys`, e.g. xml,yaml")
+		autoArg   = flag.String("auto", "", "add missing `tags` named after the field, e.g. json:camelcase,db:snakecase")
+		sortArg   = flag.Bool("sort", false, "sort the tags, json first")
+		diffArg   = flag.Bool("diff", false, "print a diff instead of rewriting the file")
+	)
+	var addArgs, setArgs repeatedFlag
+	flag.Var(&addArgs, "add", "add or replace a `tag`, e.g. json:id,omitempty (repeatable)")
+	flag.Var(&setArgs, "set", "replace an existing `tag` only, e.g. json:- (repeatable)")
+	flag.Parse()
+
+	if *file == "" {
+		fail("-file is required")
+	}
+
+	sel := selector{structName: *structArg, offset: *offsetArg, all: *allArg}
+	if *lineArg != "" {
+		var err error
+		if sel.startLine, sel.endLine, err = parseLines(*lineArg); err != nil {
+			fail(err.Error())
+		}
+	}
+
+	ops, err := parseOperations(addArgs, setArgs, *removeArg, *autoArg, *sortArg)
+	if err != nil {
+		fail(err.Error())
+	}
+
+	src, err := os.ReadFile(*file)
+	if err != nil {
+		fail(err.Error())
+	}
+
+	out, err := rewrite(*file, src, sel, ops)
+	if err != nil {
+		fail(err.Error())
+	}
+
+	if *diffArg {
+		fmt.Print(unifiedDiff(*file, src, out))
+		return
+	}
+
+	if string(out) == string(src) {
+		return
+	}
+	info, err := os.Stat(*file)
+	if err != nil {
+		fail(err.Error())
+	}
+	if err := os.WriteFile(*file, out, info.Mode()); err != nil {
+		fail(err.Error())
+	}
+}
+
+func fail(msg string) {
+	fmt.Fprintln(os.Stderr, "structtags:", msg)
+	os.Exit(1)
+}
+
+// parseLines parses "10,20" or "12" into a line range
+func parseLines(str string) (start, end int, err error) {
+	parts := strings.SplitN(str, ",", 2)
+	if start, err = strconv.Atoi(parts[0]); err != nil {
+		return 0, 0, fmt.Errorf("invalid -line %q", str)
+	}
+	end = start
+	if len(parts) == 2 {
+		if end, err = strconv.Atoi(parts[1]); err != nil {
+			return 0, 0, fmt.Errorf("invalid -line %q", str)
+		}
+	}
+	if start < 1 || end < start {
+		return 0, 0, fmt.Errorf("invalid -line %q", str)
+	}
+	return
+}
+
+// repeatedFlag collects the values of a flag given more than once
+type repeatedFlag []string
+
+func (f *repeatedFlag) String() string { return strings.Join(*f, " ") }
+
+func (f *repeatedFlag) Set(str string) error {
+	*f = append(*f, str)
+	return nil
+}
+
+// parseOperations parses the operation flags
+func parseOperations(add, set []string, remove, auto string, sort bool) (ops operations, err error) {
+	for _, str := range add {
+		kv, err := parseKeyVal(str)
+		if err != nil {
+			return ops, err
+		}
+		ops.add = append(ops.add, kv)
+	}
+	for _, str := range set {
+		kv, err := parseKeyVal(str)
+		if err != nil {
+			return ops, err
+		}
+		ops.set = append(ops.set, kv)
+	}
+	if remove != "" {
+		ops.remove = strings.Split(remove, ",")
+	}
+
+	autoKeyVals, err := parseKeyVals(auto)
+	if err != nil {
+		return
+	}
+	if len(autoKeyVals) > 0 {
+		ops.auto = utils.TagNaming{}
+		for _, kv := range autoKeyVals {
+			naming, err := parseNaming(kv.val)
+			if err != nil {
+				return ops, err
+			}
+			ops.auto[kv.key] = naming
+		}
+	}
+
+	ops.sort = sort
+	return
+}
+
+// parseKeyVal parses "key:value", where value can contain anything
+func parseKeyVal(str string) (keyVal, error) {
+	i := strings.Index(str, ":")
+	if i <= 0 {
+		return keyVal{}, fmt.Errorf("invalid key:value %q", str)
+	}
+	return keyVal{str[:i], str[i+1:]}, nil
+}
+
+// parseKeyVals parses "key1:val1,key2:val2"
+// Values can not contain commas.
+func parseKeyVals(str string) (keyVals []keyVal, err error) {
+	if str == "" {
+		return
+	}
+	for _, part := range strings.Split(str, ",") {
+		kv, err := parseKeyVal(part)
+		if err != nil {
+			return nil, err
+		}
+		keyVals = append(keyVals, kv)
+	}
+	return
+}
+
+func parseNaming(str string) (utils.NamingStrategy, error) {
+	switch strings.ToLower(str) {
+	case "keep", "go":
+		return utils.GoNaming, nil
+	case "snakecase", "snake":
+		return utils.SnakeCaseNaming, nil
+	case "kebabcase", "kebab":
+		return utils.KebabCaseNaming, nil
+	case "camelcase", "camel":
+		return utils.CamelCaseNaming, nil
+	case "pascalcase", "pascal":
+		return utils.PascalCaseNaming, nil
+	}
+	return 0, fmt.Errorf("unknown naming %q, use snakecase, kebabcase, camelcase, pascalcase or keep", str)
+}
@@ -0,0 +1,317 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/nosco/go-utils"
+)
+
+// selector selects the struct fields to rewrite
+type selector struct {
+	structName         string
+	startLine, endLine int
+	offset             int // -1 if not set
+	all                bool
+}
+
+type keyVal struct {
+	key, val string
+}
+
+// operations are the changes to make to each selected tag
+type operations struct {
+	remove []string
+	set    []keyVal
+	add    []keyVal
+	auto   utils.TagNaming
+	sort   bool
+}
+
+// apply applies the operations to the tag of the field with the given name
+// name is empty for embedded fields, which never get automatic tags.
+func (ops operations) apply(name string, tag utils.TagString) utils.TagString {
+	if len(ops.remove) > 0 {
+		_ = tag.RemoveMulti(ops.remove)
+	}
+	for _, kv := range ops.set {
+		tag.Set(kv.key, kv.val)
+	}
+	for _, kv := range ops.add {
+		tag.Add(kv.key, kv.val)
+	}
+	if len(ops.auto) > 0 && name != "" {
+		tag = ops.auto.Apply(name, tag)
+	}
+	if ops.sort {
+		tag.Sort()
+	}
+	return tag
+}
+
+// edit replaces src[start:end] with text
+type edit struct {
+	start, end int
+	text       string
+}
+
+// rewrite applies ops to the tags of the fields selected by sel
+// Only the tag literals are changed. If src was gofmt'ed, the result is too,
+// so that tags stay aligned.
+func rewrite(filename string, src []byte, sel selector, ops operations) ([]byte, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, filename, src, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+
+	fields, err := selectFields(fset, file, sel)
+	if err != nil {
+		return nil, err
+	}
+
+	tokFile := fset.File(file.Pos())
+	var edits []edit
+	for _, field := range fields {
+		var tag utils.TagString
+		if field.Tag != nil {
+			val, err := strconv.Unquote(field.Tag.Value)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %v", fset.Position(field.Tag.Pos()), err)
+			}
+			tag = utils.TagString(val)
+		}
+
+		name := ""
+		if len(field.Names) > 0 {
+			name = field.Names[0].Name
+		}
+		if len(field.Names) > 1 && len(ops.auto) > 0 {
+			// The names would share one tag, named after the first
+			names := make([]string, len(field.Names))
+			for i, ident := range field.Names {
+				names[i] = ident.Name
+			}
+			return nil, fmt.Errorf("%s: can't add automatic tags to %s, declare them separately",
+				fset.Position(field.Pos()), strings.Join(names, ", "))
+		}
+
+		newTag := ops.apply(name, tag)
+		if newTag == tag {
+			continue
+		}
+
+		typeEnd := tokFile.Offset(field.Type.End())
+		switch {
+		case newTag == "":
+			edits = append(edits, edit{typeEnd, tokFile.Offset(field.Tag.End()), ""})
+		case field.Tag == nil:
+			edits = append(edits, edit{typeEnd, typeEnd, " " + quoteTag(newTag)})
+		default:
+			edits = append(edits, edit{tokFile.Offset(field.Tag.Pos()), tokFile.Offset(field.Tag.End()), quoteTag(newTag)})
+		}
+	}
+
+	if len(edits) == 0 {
+		return src, nil
+	}
+
+	// Apply from the end, so earlier offsets stay valid
+	sort.Slice(edits, func(i, j int) bool { return edits[i].start > edits[j].start })
+	out := append([]byte{}, src...)
+	for _, e := range edits {
+		out = append(out[:e.start], append([]byte(e.text), out[e.end:]...)...)
+	}
+
+	if formatted, err := format.Source(src); err == nil && bytes.Equal(formatted, src) {
+		return format.Source(out)
+	}
+	return out, nil
+}
+
+// quoteTag returns tag as a raw string literal if possible
+func quoteTag(tag utils.TagString) string {
+	if strings.ContainsAny(string(tag), "`\r") {
+		return strconv.Quote(string(tag))
+	}
+	return "`" + string(tag) + "`"
+}
+
+// selectFields returns the struct fields selected by sel
+func selectFields(fset *token.FileSet, file *ast.File, sel selector) (fields []*ast.Field, err error) {
+	selectors := 0
+	for _, set := range []bool{sel.structName != "", sel.startLine > 0, sel.offset >= 0, sel.all} {
+		if set {
+			selectors++
+		}
+	}
+	if selectors != 1 {
+		return nil, errors.New("exactly one of -struct, -line, -offset and -all must be given")
+	}
+
+	switch {
+	case sel.structName != "":
+		ast.Inspect(file, func(n ast.Node) bool {
+			if spec, ok := n.(*ast.TypeSpec); ok && spec.Name.Name == sel.structName {
+				if st, ok := spec.Type.(*ast.StructType); ok {
+					fields = append(fields, structFields(st)...)
+				}
+				return false
+			}
+			return true
+		})
+		if fields == nil {
+			return nil, fmt.Errorf("struct %s not found", sel.structName)
+		}
+
+	case sel.startLine > 0:
+		ast.Inspect(file, func(n ast.Node) bool {
+			if st, ok := n.(*ast.StructType); ok {
+				for _, field := range st.Fields.List {
+					line := fset.Position(field.Pos()).Line
+					if line >= sel.startLine && line <= sel.endLine {
+						fields = append(fields, field)
+					}
+				}
+			}
+			return true
+		})
+
+	case sel.offset >= 0:
+		tokFile := fset.File(file.Pos())
+		if sel.offset > tokFile.Size() {
+			return nil, fmt.Errorf("offset %d is beyond the end of the file", sel.offset)
+		}
+		pos := tokFile.Pos(sel.offset)
+		var inner *ast.StructType
+		ast.Inspect(file, func(n ast.Node) bool {
+			if n == nil || pos < n.Pos() || pos >= n.End() {
+				return false
+			}
+			if st, ok := n.(*ast.StructType); ok {
+				inner = st
+			}
+			return true
+		})
+		if inner == nil {
+			return nil, fmt.Errorf("no struct at offset %d", sel.offset)
+		}
+		fields = structFields(inner)
+
+	case sel.all:
+		ast.Inspect(file, func(n ast.Node) bool {
+			if st, ok := n.(*ast.StructType); ok {
+				fields = append(fields, st.Fields.List...)
+			}
+			return true
+		})
+	}
+
+	if len(fields) == 0 {
+		return nil, errors.New("no struct fields selected")
+	}
+	return
+}
+
+// structFields returns the fields of st, including those of nested structs
+func structFields(st *ast.StructType) (fields []*ast.Field) {
+	ast.Inspect(st, func(n ast.Node) bool {
+		if nested, ok := n.(*ast.StructType); ok {
+			fields = append(fields, nested.Fields.List...)
+		}
+		return true
+	})
+	return
+}
+
+// unifiedDiff returns a unified diff of old and new
+// Rewriting tags never adds or removes lines, so lines are compared one by
+// one. Should the line counts differ anyway, the whole file is shown as changed.
+func unifiedDiff(filename string, old, new []byte) string {
+	if bytes.Equal(old, new) {
+		return ""
+	}
+
+	oldLines := splitLines(old)
+	newLines := splitLines(new)
+
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "--- %s\n+++ %s\n", filename, filename)
+
+	if len(oldLines) != len(newLines) {
+		fmt.Fprintf(&buf, "@@ -1,%d +1,%d @@\n", len(oldLines), len(newLines))
+		for _, line := range oldLines {
+			writeDiffLine(&buf, '-', line)
+		}
+		for _, line := range newLines {
+			writeDiffLine(&buf, '+', line)
+		}
+		return buf.String()
+	}
+
+	const context = 3
+	for i := 0; i < len(oldLines); {
+		if oldLines[i] == newLines[i] {
+			i++
+			continue
+		}
+
+		// Merge changes less than 2*context lines apart into one hunk
+		start := max(i-context, 0)
+		end := i
+		for j := i + 1; j < len(oldLines) && j <= end+2*context; j++ {
+			if oldLines[j] != newLines[j] {
+				end = j
+			}
+		}
+		stop := min(end+context+1, len(oldLines))
+
+		fmt.Fprintf(&buf, "@@ -%d,%d +%d,%d @@\n", start+1, stop-start, start+1, stop-start)
+		for j := start; j < stop; {
+			if oldLines[j] == newLines[j] {
+				writeDiffLine(&buf, ' ', oldLines[j])
+				j++
+				continue
+			}
+			k := j
+			for k < stop && oldLines[k] != newLines[k] {
+				k++
+			}
+			for _, line := range oldLines[j:k] {
+				writeDiffLine(&buf, '-', line)
+			}
+			for _, line := range newLines[j:k] {
+				writeDiffLine(&buf, '+', line)
+			}
+			j = k
+		}
+		i = stop
+	}
+
+	return buf.String()
+}
+
+// splitLines splits src into lines, keeping the line endings
+func splitLines(src []byte) []string {
+	lines := strings.SplitAfter(string(src), "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+func writeDiffLine(buf *strings.Builder, prefix byte, line string) {
+	buf.WriteByte(prefix)
+	buf.WriteString(line)
+	if !strings.HasSuffix(line, "\n") {
+		buf.WriteString("\n\\ No newline at end of file\n")
+	}
+}
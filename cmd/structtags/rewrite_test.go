@@ -0,0 +1,203 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/nosco/go-utils"
+)
+
+const testSrc = `package models
+
+// User is a user
+type User struct {
+	ID       int    // The primary key
+	UserName string ` + "`json:\"user_name\" xml:\"name\"`" + `
+	Address  struct {
+		StreetName string
+	}
+	Embedded
+}
+
+type Other struct {
+	Value string
+}
+`
+
+func TestRewriteStruct(t *testing.T) {
+	ops := operations{
+		remove: []string{"xml"},
+		auto:   utils.TagNaming{"json": utils.CamelCaseNaming, "db": utils.SnakeCaseNaming},
+		sort:   true,
+	}
+	out, err := rewrite("user.go", []byte(testSrc), selector{structName: "User", offset: -1}, ops)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `package models
+
+// User is a user
+type User struct {
+	ID       int    ` + "`json:\"id\" db:\"id\"`" + ` // The primary key
+	UserName string ` + "`json:\"user_name\" db:\"user_name\"`" + `
+	Address  struct {
+		StreetName string ` + "`json:\"streetName\" db:\"street_name\"`" + `
+	} ` + "`json:\"address\" db:\"address\"`" + `
+	Embedded
+}
+
+type Other struct {
+	Value string
+}
+`
+	if string(out) != expected {
+		t.Errorf("got\n%s\nexpected\n%s", out, expected)
+	}
+}
+
+func TestRewriteSelectors(t *testing.T) {
+	ops := operations{add: []keyVal{{"validate", "required"}}}
+	samples := []struct {
+		sel     selector
+		changed []string
+	}{
+		{selector{startLine: 5, endLine: 5, offset: -1}, []string{"ID"}},
+		{selector{startLine: 6, endLine: 8, offset: -1}, []string{"UserName", "Address", "StreetName"}},
+		{selector{offset: strings.Index(testSrc, "StreetName")}, []string{"StreetName"}},
+		{selector{offset: strings.Index(testSrc, "Value")}, []string{"Value"}},
+		{selector{all: true, offset: -1}, []string{"ID", "UserName", "Address", "StreetName", "Embedded", "Value"}},
+	}
+
+	for _, sample := range samples {
+		out, err := rewrite("user.go", []byte(testSrc), sample.sel, ops)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n := strings.Count(string(out), `validate:"required"`); n != len(sample.changed) {
+			t.Errorf("got %d changed fields with %+v, expected %d (%v):\n%s", n, sample.sel, len(sample.changed), sample.changed, out)
+		}
+	}
+
+	errorSels := []selector{
+		{offset: -1},
+		{structName: "User", all: true, offset: -1},
+		{structName: "Missing", offset: -1},
+		{startLine: 100, endLine: 200, offset: -1},
+		{offset: 0},
+	}
+	for _, sel := range errorSels {
+		if _, err := rewrite("user.go", []byte(testSrc), sel, ops); err == nil {
+			t.Errorf("Expected an error with %+v", sel)
+		}
+	}
+}
+
+func TestRewriteAddOptions(t *testing.T) {
+	ops, err := parseOperations([]string{"json:id,omitempty"}, []string{"xml:name,attr"}, "", "", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := rewrite("user.go", []byte(testSrc), selector{startLine: 5, endLine: 6, offset: -1}, ops)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(out), "\tID       int    `json:\"id,omitempty\"` // The primary key\n") ||
+		!strings.Contains(string(out), "\tUserName string `json:\"id,omitempty\" xml:\"name,attr\"`\n") {
+		t.Errorf("got\n%s", out)
+	}
+}
+
+func TestRewriteRemoveAll(t *testing.T) {
+	ops := operations{remove: []string{"json", "xml"}}
+	out, err := rewrite("user.go", []byte(testSrc), selector{startLine: 6, endLine: 6, offset: -1}, ops)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(out), "\tUserName string\n") {
+		t.Errorf("The empty tag was not removed:\n%s", out)
+	}
+}
+
+func TestRewriteUnformatted(t *testing.T) {
+	src := "package p\ntype T struct{\n  A  int   // comment\n  BB string\n}\n"
+	out, err := rewrite("t.go", []byte(src), selector{structName: "T", offset: -1}, operations{add: []keyVal{{"json", "-"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "package p\ntype T struct{\n  A  int `json:\"-\"`   // comment\n  BB string `json:\"-\"`\n}\n"
+	if string(out) != expected {
+		t.Errorf("got\n%q\nexpected\n%q", out, expected)
+	}
+}
+
+func TestRewriteSharedDeclaration(t *testing.T) {
+	src := "package p\n\ntype T struct {\n\tFirst, Last string\n}\n"
+	sel := selector{structName: "T", offset: -1}
+
+	ops := operations{auto: utils.TagNaming{"json": utils.CamelCaseNaming}}
+	_, err := rewrite("t.go", []byte(src), sel, ops)
+	if err == nil || !strings.Contains(err.Error(), "t.go:4:2: can't add automatic tags to First, Last") {
+		t.Errorf("got %v, expected an error for the shared declaration", err)
+	}
+
+	// Other operations apply to the shared tag
+	out, err := rewrite("t.go", []byte(src), sel, operations{add: []keyVal{{"validate", "required"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(out), "\tFirst, Last string `validate:\"required\"`\n") {
+		t.Errorf("got\n%s", out)
+	}
+}
+
+func TestUnifiedDiff(t *testing.T) {
+	old := "a\nb\nc\nd\ne\nf\ng\nh\ni\nj\nk\nl\nm\n"
+	new := "a\nB\nc\nd\ne\nf\ng\nh\ni\nj\nk\nL\nm\n"
+	expected := `--- f.go
++++ f.go
+@@ -1,5 +1,5 @@
+ a
+-b
++B
+ c
+ d
+ e
+@@ -9,5 +9,5 @@
+ i
+ j
+ k
+-l
++L
+ m
+`
+	if out := unifiedDiff("f.go", []byte(old), []byte(new)); out != expected {
+		t.Errorf("got\n%s\nexpected\n%s", out, expected)
+	}
+	if out := unifiedDiff("f.go", []byte(old), []byte(old)); out != "" {
+		t.Errorf("got %q for equal input, expected nothing", out)
+	}
+}
+
+func TestParseOperations(t *testing.T) {
+	ops, err := parseOperations([]string{"validate:required", "json:id,omitempty"}, []string{"xml:-,"}, "xml,yaml", "json:camelcase,db:snake", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ops.add) != 2 || ops.add[0] != (keyVal{"validate", "required"}) || ops.add[1] != (keyVal{"json", "id,omitempty"}) {
+		t.Errorf("got add %+v", ops.add)
+	}
+	if len(ops.set) != 1 || ops.set[0] != (keyVal{"xml", "-,"}) {
+		t.Errorf("got set %+v", ops.set)
+	}
+	if len(ops.remove) != 2 || ops.auto["json"] != utils.CamelCaseNaming || ops.auto["db"] != utils.SnakeCaseNaming || !ops.sort {
+		t.Errorf("got %+v", ops)
+	}
+
+	if _, err := parseOperations(nil, nil, "", "json:shouting", false); err == nil {
+		t.Error("Expected an error for an unknown naming")
+	}
+	if _, err := parseOperations([]string{"json"}, nil, "", "", false); err == nil {
+		t.Error("Expected an error for a missing value")
+	}
+}
@@ -0,0 +1,275 @@
+package utils
+
+import (
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+)
+
+// DefaultInitialisms are the initialisms known to DefaultConverter.
+// The list follows the one used by golint for Go identifiers.
+var DefaultInitialisms = []string{
+	"ACL", "API", "ASCII", "CPU", "CSS", "DNS", "EOF", "GUID", "HTML", "HTTP",
+	"HTTPS", "ID", "IP", "JSON", "LHS", "QPS", "RAM", "RHS", "RPC", "SLA",
+	"SMTP", "SQL", "SSH", "TCP", "TLS", "TTL", "UDP", "UI", "UID", "UUID",
+	"URI", "URL", "UTF8", "VM", "XML", "XMPP", "XSRF", "XSS",
+}
+
+// DefaultConverter backs the package level case functions.
+// Initialisms added to it are honored by CamelCase, PascalCase, SnakeCase and
+// KebabCase.
+var DefaultConverter = NewConverter(DefaultInitialisms...)
+
+// Converter converts strings between naming conventions while keeping
+// initialisms like ID, URL and HTTP intact, so that "user_id" becomes "UserID"
+// and "UserID" becomes "user_id" again.
+type Converter struct {
+	mu          sync.RWMutex
+	initialisms map[string]bool
+}
+
+// NewConverter returns a Converter that knows the given initialisms
+func NewConverter(initialisms ...string) *Converter {
+	c := &Converter{initialisms: map[string]bool{}}
+	c.AddInitialisms(initialisms...)
+	return c
+}
+
+// AddInitialisms registers one or more initialisms, e.g. "SKU" or "GRPC"
+func (c *Converter) AddInitialisms(initialisms ...string) {
+	c.mu.Lock()
+	for _, initialism := range initialisms {
+		if initialism != "" {
+			c.initialisms[strings.ToUpper(initialism)] = true
+		}
+	}
+	c.mu.Unlock()
+}
+
+// RemoveInitialisms unregisters one or more initialisms
+func (c *Converter) RemoveInitialisms(initialisms ...string) {
+	c.mu.Lock()
+	for _, initialism := range initialisms {
+		delete(c.initialisms, strings.ToUpper(initialism))
+	}
+	c.mu.Unlock()
+}
+
+// IsInitialism reports whether word is a registered initialism
+// The check is case insensitive
+func (c *Converter) IsInitialism(word string) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.initialisms[strings.ToUpper(word)]
+}
+
+// PascalCase converts str to e.g. "UserID"
+func (c *Converter) PascalCase(str string) string {
+	words := c.Words(str)
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	var buf strings.Builder
+	for _, word := range words {
+		buf.WriteString(c.title(word))
+	}
+	return buf.String()
+}
+
+// CamelCase converts str to e.g. "userID"
+// The first word is always lower cased, even if it is an initialism.
+func (c *Converter) CamelCase(str string) string {
+	words := c.Words(str)
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	var buf strings.Builder
+	for i, word := range words {
+		if i == 0 {
+			buf.WriteString(strings.ToLower(word))
+		} else {
+			buf.WriteString(c.title(word))
+		}
+	}
+	return buf.String()
+}
+
+// SnakeCase converts str to e.g. "user_id"
+func (c *Converter) SnakeCase(str string) string {
+	return c.join(str, "_")
+}
+
+// KebabCase converts str to e.g. "user-id"
+func (c *Converter) KebabCase(str string) string {
+	return c.join(str, "-")
+}
+
+func (c *Converter) join(str, sep string) string {
+	words := c.Words(str)
+	for i, word := range words {
+		words[i] = strings.ToLower(word)
+	}
+	return strings.Join(words, sep)
+}
+
+// title upper cases initialisms and title cases all other words
+// The caller must hold the read lock.
+func (c *Converter) title(word string) string {
+	upper := strings.ToUpper(word)
+	if c.initialisms[upper] {
+		return upper
+	}
+	// Initialisms written in upper case followed by lower case letters, e.g.
+	// "IDs" or "IPv4", but not "ids" or "rams"
+	if n := upperPrefixLen(word); n > 0 && n < len(word) && c.initialisms[word[:n]] {
+		return word[:n] + strings.ToLower(word[n:])
+	}
+	return titleFirst(strings.ToLower(word))
+}
+
+// upperPrefixLen returns the length in bytes of the upper case letters and
+// digits str starts with
+func upperPrefixLen(str string) int {
+	for i, r := range str {
+		if !isUpperRune(r) && !unicode.IsDigit(r) {
+			return i
+		}
+	}
+	return len(str)
+}
+
+// splitInitialisms splits str into registered initialisms, trying longer ones
+// first, or returns nil if it can't be split
+// The caller must hold the read lock.
+func (c *Converter) splitInitialisms(str string) []string {
+	if c.initialisms[str] {
+		return []string{str}
+	}
+	for i := len(str) - 1; i > 0; i-- {
+		if !utf8.RuneStart(str[i]) || !c.initialisms[str[:i]] {
+			continue
+		}
+		if rest := c.splitInitialisms(str[i:]); rest != nil {
+			return append([]string{str[:i]}, rest...)
+		}
+	}
+	return nil
+}
+
+// titleFirst title cases the first rune of str
+// Title case differs from upper case for digraphs like "ǆ", which becomes "ǅ".
+func titleFirst(str string) string {
+	r, size := utf8.DecodeRuneInString(str)
+	if size == 0 || r == utf8.RuneError {
+		return str
+	}
+	return string(unicode.ToTitle(r)) + str[size:]
+}
+
+// Words splits str into words
+// Unicode letters and digits make up words, everything else separates them.
+// Within a run of letters and digits a new word starts at an upper case letter
+// following anything but another upper case letter, and before the last upper
+// case letter of an upper case run followed by lower case letters
+// ("HTTPServer" is split into "HTTP" and "Server"), unless that would split a
+// registered initialism ("UserIDs" is split into "User" and "IDs", "IPv4" is
+// one word). A plural "s" stays with the initialisms before it ("UserUIDs" is
+// split into "User" and "UIDs"). Upper case runs made of registered initialisms are split into
+// them, longest first ("HTTPSURL" is split into "HTTPS" and "URL"). Letters
+// without case, like Japanese kana, are never split from each other, and
+// combining marks stay with the letter they modify.
+func (c *Converter) Words(str string) (words []string) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	start := -1 // Byte offset of the current word
+	var prev rune
+	var prevPos int
+
+	for i := 0; i <= len(str); {
+		if i == len(str) {
+			if start >= 0 {
+				words = append(words, str[start:])
+			}
+			break
+		}
+
+		r, size := utf8.DecodeRuneInString(str[i:])
+		switch {
+		case !isWordRune(r):
+			if start >= 0 {
+				words = append(words, str[start:i])
+				start = -1
+			}
+
+		case unicode.IsMark(r):
+			// Marks belong to the previous letter and are dropped if stray
+
+		case start < 0:
+			start = i
+
+		case isUpperRune(r) && !isUpperRune(prev):
+			words = append(words, str[start:i])
+			start = i
+
+		case unicode.IsLower(r) && isUpperRune(prev) && prevPos > start:
+			// An upper case run followed by lower case letters, which start a
+			// new word unless the run is made of initialisms and only a plural
+			// "s" follows ("UIDs") or the split would break an initialism
+			plural := r == 's' && !unicode.IsLower(nextRune(str[i+size:]))
+			if c.splitInitialisms(str[start:i]) == nil || !plural && c.splitInitialisms(str[start:prevPos]) != nil {
+				words = append(words, str[start:prevPos])
+				start = prevPos
+			}
+		}
+
+		if start >= 0 && !unicode.IsMark(r) {
+			prev, prevPos = r, i
+		}
+		i += size
+	}
+
+	return c.splitWords(words)
+}
+
+// nextRune returns the first rune of str, or utf8.RuneError if str is empty
+func nextRune(str string) rune {
+	r, _ := utf8.DecodeRuneInString(str)
+	return r
+}
+
+// splitWords splits the upper case start of each word into initialisms, the
+// last of which keeps the rest of the word
+// The caller must hold the read lock.
+func (c *Converter) splitWords(words []string) []string {
+	var split []string
+	for i, word := range words {
+		n := upperPrefixLen(word)
+		initialisms := c.splitInitialisms(word[:n])
+		if len(initialisms) < 2 {
+			if split != nil {
+				split = append(split, word)
+			}
+			continue
+		}
+		if split == nil {
+			split = append(split, words[:i]...)
+		}
+		last := len(initialisms) - 1
+		split = append(split, initialisms[:last]...)
+		split = append(split, initialisms[last]+word[n:])
+	}
+	if split == nil {
+		return words
+	}
+	return split
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.IsMark(r)
+}
+
+func isUpperRune(r rune) bool {
+	return unicode.IsUpper(r) || unicode.IsTitle(r)
+}
@@ -0,0 +1,173 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestConverterPascalCase(t *testing.T) {
+	samples := []sample{
+		{"user_id", "UserID"},
+		{"userId", "UserID"},
+		{"user_ids", "UserIds"},
+		{"user_IDs", "UserIDs"},
+		{"UserIDs", "UserIDs"},
+		{"rams", "Rams"},
+		{"RAMs", "RAMs"},
+		{"api_url", "APIURL"},
+		{"MyHTTPSURL", "MyHTTPSURL"},
+		{"IPv4Address", "IPv4Address"},
+		{"http_server", "HTTPServer"},
+		{"HTTPServer", "HTTPServer"},
+		{"json-api", "JSONAPI"},
+		{"uuid", "UUID"},
+		{"utf8_string", "UTF8String"},
+		{"idea", "Idea"},
+		{"sample text", "SampleText"},
+	}
+
+	for _, sample := range samples {
+		if out := DefaultConverter.PascalCase(sample.str); out != sample.out {
+			t.Errorf("got %q from %q, expected %q", out, sample.str, sample.out)
+		}
+	}
+}
+
+func TestConverterCamelCase(t *testing.T) {
+	samples := []sample{
+		{"user_id", "userID"},
+		{"id", "id"},
+		{"id_user", "idUser"},
+		{"HTTPServer", "httpServer"},
+		{"UIDs", "uids"},
+		{"remote_ip_address", "remoteIPAddress"},
+		{"sample text", "sampleText"},
+	}
+
+	for _, sample := range samples {
+		if out := DefaultConverter.CamelCase(sample.str); out != sample.out {
+			t.Errorf("got %q from %q, expected %q", out, sample.str, sample.out)
+		}
+	}
+}
+
+func TestConverterSnakeCase(t *testing.T) {
+	samples := []sample{
+		{"UserID", "user_id"},
+		{"userID", "user_id"},
+		{"UserIDs", "user_ids"},
+		{"HTTPServer", "http_server"},
+		{"APIURL", "api_url"},
+		{"MyHTTPSURL", "my_https_url"},
+		{"IPv4Address", "ipv4_address"},
+		{"APIURLs", "api_urls"},
+		{"UIDatePicker", "ui_date_picker"},
+		{"UIDs", "uids"},
+		{"UserUIDs", "user_uids"},
+		{"remoteIPAddress", "remote_ip_address"},
+		{"UTF8String", "utf8_string"},
+	}
+
+	for _, sample := range samples {
+		if out := DefaultConverter.SnakeCase(sample.str); out != sample.out {
+			t.Errorf("got %q from %q, expected %q", out, sample.str, sample.out)
+		}
+	}
+}
+
+func TestConverterRoundTrip(t *testing.T) {
+	samples := []string{"user_id", "http_server", "remote_ip_address", "user_ids", "created_at",
+		"my_https_url", "api_url", "ipv4_address", "user_uids", "ui_date_picker"}
+
+	for _, str := range samples {
+		pascal := DefaultConverter.PascalCase(str)
+		if out := DefaultConverter.SnakeCase(pascal); out != str {
+			t.Errorf("got %q from %q via %q, expected %q", out, str, pascal, str)
+		}
+		camel := DefaultConverter.CamelCase(str)
+		if out := DefaultConverter.SnakeCase(camel); out != str {
+			t.Errorf("got %q from %q via %q, expected %q", out, str, camel, str)
+		}
+	}
+
+	// Go identifiers through snake_case, where lower case plurals and mixed case
+	// initialisms can't be told from other words
+	identifiers := []sample{
+		{"UserID", "UserID"},
+		{"HTTPServer", "HTTPServer"},
+		{"APIURL", "APIURL"},
+		{"MyHTTPSURL", "MyHTTPSURL"},
+		{"UserIDs", "UserIds"},
+		{"UserUIDs", "UserUids"},
+		{"UIDatePicker", "UIDatePicker"},
+		{"IPv4Address", "Ipv4Address"},
+	}
+	for _, sample := range identifiers {
+		snake := DefaultConverter.SnakeCase(sample.str)
+		if out := DefaultConverter.PascalCase(snake); out != sample.out {
+			t.Errorf("got %q from %q via %q, expected %q", out, sample.str, snake, sample.out)
+		}
+	}
+}
+
+func TestConverterCustomInitialisms(t *testing.T) {
+	c := NewConverter("ID")
+	if out := c.PascalCase("product_sku"); out != "ProductSku" {
+		t.Errorf("got %q, expected %q before adding SKU", out, "ProductSku")
+	}
+
+	c.AddInitialisms("sku")
+	if !c.IsInitialism("Sku") {
+		t.Error("sku was not registered as an initialism")
+	}
+	if out := c.PascalCase("product_sku"); out != "ProductSKU" {
+		t.Errorf("got %q, expected %q", out, "ProductSKU")
+	}
+	if out := c.SnakeCase("ProductSKU"); out != "product_sku" {
+		t.Errorf("got %q, expected %q", out, "product_sku")
+	}
+	if out := c.PascalCase("api_url"); out != "ApiUrl" {
+		t.Errorf("got %q, expected %q - only registered initialisms should be used", out, "ApiUrl")
+	}
+
+	c.RemoveInitialisms("SKU")
+	if out := c.PascalCase("product_sku"); out != "ProductSku" {
+		t.Errorf("got %q, expected %q after removing SKU", out, "ProductSku")
+	}
+}
+
+func TestConverterWords(t *testing.T) {
+	samples := []struct {
+		str   string
+		words []string
+	}{
+		{"", nil},
+		{"HTTPServer", []string{"HTTP", "Server"}},
+		{"userIDs", []string{"user", "IDs"}},
+		{"MyHTTPSURL", []string{"My", "HTTPS", "URL"}},
+		{"APIURLs", []string{"API", "URLs"}},
+		{"IPv4Address", []string{"IPv4", "Address"}},
+		{"UserUIDs", []string{"User", "UIDs"}},
+		{"rams", []string{"rams"}},
+		{"Base64Encode", []string{"Base64", "Encode"}},
+		{"ÆRØCafé", []string{"ÆRØ", "Café"}},
+		{"ÉtéÀParis", []string{"Été", "À", "Paris"}},
+		{"東京タワーTokyo", []string{"東京タワー", "Tokyo"}},
+		{"東京TOKYO", []string{"東京", "TOKYO"}},
+		{"\u0301foo", []string{"foo"}},
+		{"foo\xffbar", []string{"foo", "bar"}},
+	}
+
+	for _, sample := range samples {
+		words := DefaultConverter.Words(sample.str)
+		if strings.Join(words, "|") != strings.Join(sample.words, "|") {
+			t.Errorf("got %q from %q, expected %q", words, sample.str, sample.words)
+		}
+	}
+}
+
+func BenchmarkConverterPascalCase(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		_ = DefaultConverter.PascalCase("remote_ip_address_for_user_id")
+	}
+}
@@ -0,0 +1,118 @@
+package utils
+
+import (
+	"errors"
+	"reflect"
+)
+
+// Duplicate is a value that occurs more than once in a slice
+type Duplicate[T any] struct {
+	Value   T     // The first occurrence
+	Indices []int // The indices of all occurrences, in order
+}
+
+// Count returns the number of occurrences
+func (d Duplicate[T]) Count() int {
+	return len(d.Indices)
+}
+
+// DuplicateValues returns the values that occur more than once in s, in the
+// order they first occur
+// It is the type safe version of Duplicates.
+func DuplicateValues[T comparable](s []T) []Duplicate[T] {
+	return DuplicatesBy(s, func(val T) T { return val })
+}
+
+// DuplicatesBy returns the values of s that share a key with other values, in
+// the order they first occur
+// e.g. the rows sharing an email, with key returning the email of a row.
+func DuplicatesBy[T any, K comparable](s []T, key func(T) K) (duplicates []Duplicate[T]) {
+	groups := map[K][]int{}
+	var keys []K
+	for i, val := range s {
+		k := key(val)
+		if _, ok := groups[k]; !ok {
+			keys = append(keys, k)
+		}
+		groups[k] = append(groups[k], i)
+	}
+
+	for _, k := range keys {
+		if indices := groups[k]; len(indices) > 1 {
+			duplicates = append(duplicates, Duplicate[T]{Value: s[indices[0]], Indices: indices})
+		}
+	}
+	return
+}
+
+// Duplicates returns the values that occur more than once in the slice arr, in
+// the order they first occur
+// Like Unique, values that can't be map keys are compared with
+// reflect.DeepEqual.
+func Duplicates(arr interface{}) (duplicates []Duplicate[interface{}], err error) {
+	if arr == nil {
+		return nil, errors.New("Not a slice, got nil")
+	}
+	arrValue := reflect.ValueOf(arr)
+	if arrValue.Kind() != reflect.Slice {
+		return nil, errors.New("Not a slice")
+	}
+
+	checkHashable := !isHashable(arrValue.Type().Elem())
+	groups := map[interface{}]int{}
+	var unhashable []interface{}
+	var unhashableGroups []int
+	var indices [][]int
+
+	for i := 0; i < arrValue.Len(); i++ {
+		val := arrValue.Index(i).Interface()
+
+		group := -1
+		if checkHashable && val != nil && !reflect.ValueOf(val).Comparable() {
+			for j, other := range unhashable {
+				if reflect.DeepEqual(other, val) {
+					group = unhashableGroups[j]
+					break
+				}
+			}
+			if group < 0 {
+				unhashable = append(unhashable, val)
+				unhashableGroups = append(unhashableGroups, len(indices))
+			}
+		} else if g, ok := groups[val]; ok {
+			group = g
+		} else {
+			groups[val] = len(indices)
+		}
+
+		if group < 0 {
+			indices = append(indices, []int{i})
+		} else {
+			indices[group] = append(indices[group], i)
+		}
+	}
+
+	for _, group := range indices {
+		if len(group) > 1 {
+			duplicates = append(duplicates, Duplicate[interface{}]{
+				Value:   arrValue.Index(group[0]).Interface(),
+				Indices: group,
+			})
+		}
+	}
+	return
+}
+
+// Frequencies returns how many times each value occurs in s
+func Frequencies[T comparable](s []T) map[T]int {
+	return CountBy(s, func(val T) T { return val })
+}
+
+// CountBy returns how many values of s have each key
+func CountBy[T any, K comparable](s []T, key func(T) K) map[K]int {
+	counts := map[K]int{}
+	for _, val := range s {
+		counts[key(val)]++
+	}
+	return counts
+}
@@ -0,0 +1,80 @@
+package utils
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDuplicateValues(t *testing.T) {
+	dups := DuplicateValues([]string{"a", "b", "a", "c", "b", "a"})
+	expected := []Duplicate[string]{
+		{Value: "a", Indices: []int{0, 2, 5}},
+		{Value: "b", Indices: []int{1, 4}},
+	}
+	if !reflect.DeepEqual(dups, expected) {
+		t.Errorf("got %+v, expected %+v", dups, expected)
+	}
+	if dups[0].Count() != 3 || dups[1].Count() != 2 {
+		t.Errorf("got counts %d and %d", dups[0].Count(), dups[1].Count())
+	}
+
+	if dups := DuplicateValues([]int{1, 2, 3}); dups != nil {
+		t.Errorf("got %+v without duplicates", dups)
+	}
+}
+
+func TestDuplicatesBy(t *testing.T) {
+	type row struct {
+		Name, Email string
+	}
+	rows := []row{
+		{"Ada", "ada@example.com"},
+		{"Bob", "bob@example.com"},
+		{"Ada L", "ADA@example.com"},
+	}
+
+	dups := DuplicatesBy(rows, func(r row) string { return strings.ToLower(r.Email) })
+	if len(dups) != 1 || dups[0].Value != rows[0] || !reflect.DeepEqual(dups[0].Indices, []int{0, 2}) {
+		t.Errorf("got %+v", dups)
+	}
+}
+
+func TestDuplicates(t *testing.T) {
+	var tests = []struct {
+		in  interface{}
+		out []Duplicate[interface{}]
+	}{
+		{[]int{1, 2, 1, 1}, []Duplicate[interface{}]{{1, []int{0, 2, 3}}}},
+		{[][]int{{1}, {2}, {1}, {2}, {3}}, []Duplicate[interface{}]{{[]int{1}, []int{0, 2}}, {[]int{2}, []int{1, 3}}}},
+		{[]interface{}{nil, "a", []int{1}, nil, []int{1}, "a"}, []Duplicate[interface{}]{{nil, []int{0, 3}}, {"a", []int{1, 5}}, {[]int{1}, []int{2, 4}}}},
+		{[]string{"a", "b"}, nil},
+		{[]int(nil), nil},
+	}
+
+	for _, test := range tests {
+		out, err := Duplicates(test.in)
+		if err != nil || !reflect.DeepEqual(out, test.out) {
+			t.Errorf("got %+v, %v from %v, expected %+v", out, err, test.in, test.out)
+		}
+	}
+
+	if _, err := Duplicates(nil); err == nil {
+		t.Error("Expected an error for nil")
+	}
+	if _, err := Duplicates("abc"); err == nil {
+		t.Error("Expected an error for a non-slice")
+	}
+}
+
+func TestFrequencies(t *testing.T) {
+	freqs := Frequencies([]string{"a", "b", "a"})
+	if !reflect.DeepEqual(freqs, map[string]int{"a": 2, "b": 1}) {
+		t.Errorf("got %v", freqs)
+	}
+
+	counts := CountBy([]string{"go", "c", "zig", "d"}, func(s string) int { return len(s) })
+	if !reflect.DeepEqual(counts, map[int]int{1: 2, 2: 1, 3: 1}) {
+		t.Errorf("got %v", counts)
+	}
+}
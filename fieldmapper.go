@@ -0,0 +1,292 @@
+package utils
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+	"sync"
+)
+
+// NamingStrategy decides how a Go field name is turned into an external name
+type NamingStrategy int
+
+const (
+	GoNaming         NamingStrategy = iota // Keep the Go field name, e.g. "UserID"
+	SnakeCaseNaming                        // e.g. "user_id"
+	KebabCaseNaming                        // e.g. "user-id"
+	CamelCaseNaming                        // e.g. "userID"
+	PascalCaseNaming                       // e.g. "UserID"
+)
+
+// Name converts the Go field name according to the strategy
+func (s NamingStrategy) Name(field string) string {
+	switch s {
+	case SnakeCaseNaming:
+		return SnakeCase(field)
+	case KebabCaseNaming:
+		return KebabCase(field)
+	case CamelCaseNaming:
+		return CamelCase(field)
+	case PascalCaseNaming:
+		return PascalCase(field)
+	}
+	return field
+}
+
+// FieldMapping maps a single Go struct field to its external name
+type FieldMapping struct {
+	Field string // Go field name
+	Name  string // External name, e.g. a column or a JSON key
+	Index []int  // Index sequence for reflect.Value.FieldByIndex
+}
+
+// FieldMap is a bidirectional map of Go field names and external names
+type FieldMap struct {
+	Fields []FieldMapping // In declaration order
+
+	byField map[string]int
+	byName  map[string]int
+}
+
+// Name returns the external name of the Go field
+func (m *FieldMap) Name(field string) (name string, ok bool) {
+	i, ok := m.byField[field]
+	if ok {
+		name = m.Fields[i].Name
+	}
+	return
+}
+
+// Field returns the Go field name of the external name
+func (m *FieldMap) Field(name string) (field string, ok bool) {
+	i, ok := m.byName[name]
+	if ok {
+		field = m.Fields[i].Field
+	}
+	return
+}
+
+// ByName returns the full mapping of the external name
+func (m *FieldMap) ByName(name string) (mapping FieldMapping, ok bool) {
+	i, ok := m.byName[name]
+	if ok {
+		mapping = m.Fields[i]
+	}
+	return
+}
+
+// FieldMapper maps Go struct fields to external names
+// The names are taken from a struct tag if one is set, and otherwise derived
+// from the Go field name with a NamingStrategy. Maps are cached per type, so a
+// FieldMapper should be created once and reused.
+//
+// Unexported fields and fields tagged "-" are ignored. The fields of embedded
+// structs are promoted, unless the embedded struct has a name in the tag.
+// Like in Go, a field shadows fields with the same name in embedded structs.
+// Like in encoding/json, fields at the same depth with the same external name
+// are all dropped, unless exactly one of them is named in the tag. Fields at
+// the same depth with the same Go name can only be looked up by external name.
+type FieldMapper struct {
+	tag    string
+	naming NamingStrategy
+	cache  sync.Map // reflect.Type -> *FieldMap
+}
+
+// NewFieldMapper returns a FieldMapper deriving all names with naming
+func NewFieldMapper(naming NamingStrategy) *FieldMapper {
+	return &FieldMapper{naming: naming}
+}
+
+// NewTagFieldMapper returns a FieldMapper that takes names from the tag
+// identified by tag, e.g. "db" or "json"
+// Fields without a name in the tag are named with naming.
+func NewTagFieldMapper(tag string, naming NamingStrategy) *FieldMapper {
+	return &FieldMapper{tag: tag, naming: naming}
+}
+
+// Map returns the FieldMap of typ, which must be a struct or a pointer to one
+func (fm *FieldMapper) Map(typ reflect.Type) (*FieldMap, error) {
+	for typ != nil && typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ == nil || typ.Kind() != reflect.Struct {
+		return nil, errors.New("Not a struct")
+	}
+
+	if m, ok := fm.cache.Load(typ); ok {
+		return m.(*FieldMap), nil
+	}
+
+	m, _ := fm.cache.LoadOrStore(typ, fm.build(typ))
+	return m.(*FieldMap), nil
+}
+
+// MapOf returns the FieldMap of the type of val
+func (fm *FieldMapper) MapOf(val interface{}) (*FieldMap, error) {
+	return fm.Map(reflect.TypeOf(val))
+}
+
+// tagName returns the name given to the field in the tag, if any
+func (fm *FieldMapper) tagName(field reflect.StructField) (name string, ignore bool) {
+	if fm.tag == "" {
+		return
+	}
+	val := TagString(field.Tag).Value(fm.tag)
+	if val == "-" {
+		return "", true
+	}
+	return val.Name(), false
+}
+
+// embedded is an embedded struct waiting to have its fields promoted
+type embedded struct {
+	typ   reflect.Type
+	index []int
+}
+
+func (fm *FieldMapper) build(typ reflect.Type) *FieldMap {
+	m := &FieldMap{
+		byField: map[string]int{},
+		byName:  map[string]int{},
+	}
+
+	// Walk the struct breadth first, so shallower fields take precedence
+	seen := map[string]bool{}      // Go field names at shallower depths
+	seenNames := map[string]bool{} // External names at shallower depths
+	ambiguous := map[string]bool{} // Go field names of more than one mapping
+	visited := map[reflect.Type]bool{typ: true}
+	level := []embedded{{typ: typ}}
+	for len(level) > 0 {
+		var next []embedded
+		var names []string
+		var candidates []fieldCandidate
+
+		for _, e := range level {
+			for i := 0; i < e.typ.NumField(); i++ {
+				field := e.typ.Field(i)
+				if seen[field.Name] {
+					continue
+				}
+				names = append(names, field.Name)
+
+				name, ignore := fm.tagName(field)
+				if ignore {
+					continue
+				}
+
+				index := append(append([]int{}, e.index...), i)
+				if field.Anonymous && name == "" {
+					ft := field.Type
+					if ft.Kind() == reflect.Ptr {
+						ft = ft.Elem()
+					}
+					if ft.Kind() == reflect.Struct {
+						// A struct embedded twice at this depth is added twice,
+						// so its fields are ambiguous
+						if !visited[ft] {
+							next = append(next, embedded{ft, index})
+						}
+						continue
+					}
+				}
+
+				if !field.IsExported() {
+					continue
+				}
+				tagged := name != ""
+				if !tagged {
+					name = fm.naming.Name(field.Name)
+				}
+				if seenNames[name] {
+					continue
+				}
+				candidates = append(candidates, fieldCandidate{FieldMapping{Field: field.Name, Name: name, Index: index}, tagged})
+			}
+		}
+
+		fields := map[string]int{}
+		for _, mapping := range dominantFields(candidates) {
+			if fields[mapping.Field]++; fields[mapping.Field] > 1 {
+				ambiguous[mapping.Field] = true
+			}
+			m.Fields = append(m.Fields, mapping)
+		}
+
+		for _, name := range names {
+			seen[name] = true
+		}
+		for _, c := range candidates {
+			seenNames[c.mapping.Name] = true
+		}
+		for _, e := range next {
+			visited[e.typ] = true
+		}
+		level = next
+	}
+
+	// Restore declaration order
+	sort.Sort(byIndex(m.Fields))
+	for i, mapping := range m.Fields {
+		if !ambiguous[mapping.Field] {
+			m.byField[mapping.Field] = i
+		}
+		m.byName[mapping.Name] = i
+	}
+
+	return m
+}
+
+// fieldCandidate is a field that may be mapped, if it doesn't conflict with
+// other fields at the same depth
+type fieldCandidate struct {
+	mapping FieldMapping
+	tagged  bool
+}
+
+// dominantFields returns the candidates that win over the others with the same
+// external name, all at the same depth
+// Like in encoding/json, a field without conflicts wins, and so does the only
+// tagged field among conflicting ones. Otherwise they are all dropped.
+func dominantFields(candidates []fieldCandidate) (mappings []FieldMapping) {
+	byName := map[string][]fieldCandidate{}
+	for _, c := range candidates {
+		byName[c.mapping.Name] = append(byName[c.mapping.Name], c)
+	}
+
+	for _, c := range candidates {
+		conflicts := byName[c.mapping.Name]
+		if len(conflicts) == 1 {
+			mappings = append(mappings, c.mapping)
+			continue
+		}
+		if !c.tagged {
+			continue
+		}
+		tagged := 0
+		for _, other := range conflicts {
+			if other.tagged {
+				tagged++
+			}
+		}
+		if tagged == 1 {
+			mappings = append(mappings, c.mapping)
+		}
+	}
+	return
+}
+
+type byIndex []FieldMapping
+
+func (a byIndex) Len() int      { return len(a) }
+func (a byIndex) Swap(i, j int) { a[i], a[j] = a[j], a[i] }
+func (a byIndex) Less(i, j int) bool {
+	for k, xik := range a[i].Index {
+		if k >= len(a[j].Index) {
+			return false
+		}
+		if xik != a[j].Index[k] {
+			return xik < a[j].Index[k]
+		}
+	}
+	return len(a[i].Index) < len(a[j].Index)
+}
@@ -0,0 +1,198 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+)
+
+type fieldMapperBase struct {
+	ID      int
+	Created string `db:"created_at"`
+}
+
+type fieldMapperAudit struct {
+	UpdatedBy string
+	Name      string
+}
+
+type fieldMapperUser struct {
+	fieldMapperBase
+	*fieldMapperAudit
+	Name      string
+	UserID    int              `db:"uid" json:"userId,omitempty"`
+	APIKey    string           `db:"-" json:"-"`
+	HTMLTitle string           `json:",omitempty"`
+	Meta      fieldMapperAudit `db:"meta"`
+	internal  string
+}
+
+func TestFieldMapperNaming(t *testing.T) {
+	samples := []struct {
+		naming NamingStrategy
+		names  []string
+	}{
+		{SnakeCaseNaming, []string{"id", "created", "updated_by", "name", "user_id", "api_key", "html_title", "meta"}},
+		{KebabCaseNaming, []string{"id", "created", "updated-by", "name", "user-id", "api-key", "html-title", "meta"}},
+		{CamelCaseNaming, []string{"id", "created", "updatedBy", "name", "userID", "apiKey", "htmlTitle", "meta"}},
+		{PascalCaseNaming, []string{"ID", "Created", "UpdatedBy", "Name", "UserID", "APIKey", "HTMLTitle", "Meta"}},
+	}
+	fields := []string{"ID", "Created", "UpdatedBy", "Name", "UserID", "APIKey", "HTMLTitle", "Meta"}
+
+	for _, sample := range samples {
+		m, err := NewFieldMapper(sample.naming).Map(reflect.TypeOf(fieldMapperUser{}))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(m.Fields) != len(fields) {
+			t.Errorf("got %d fields, expected %d: %+v", len(m.Fields), len(fields), m.Fields)
+			continue
+		}
+		for i, field := range fields {
+			if m.Fields[i].Field != field || m.Fields[i].Name != sample.names[i] {
+				t.Errorf("got %s -> %s, expected %s -> %s", m.Fields[i].Field, m.Fields[i].Name, field, sample.names[i])
+			}
+			if name, _ := m.Name(field); name != sample.names[i] {
+				t.Errorf("got %q from Name(%q), expected %q", name, field, sample.names[i])
+			}
+			if out, _ := m.Field(sample.names[i]); out != field {
+				t.Errorf("got %q from Field(%q), expected %q", out, sample.names[i], field)
+			}
+		}
+	}
+}
+
+func TestFieldMapperTag(t *testing.T) {
+	m, err := NewTagFieldMapper("db", SnakeCaseNaming).MapOf(&fieldMapperUser{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]string{
+		"ID":        "id",
+		"Created":   "created_at",
+		"UpdatedBy": "updated_by",
+		"Name":      "name",
+		"UserID":    "uid",
+		"HTMLTitle": "html_title",
+		"Meta":      "meta",
+	}
+	if len(m.Fields) != len(expected) {
+		t.Errorf("got %d fields, expected %d: %+v", len(m.Fields), len(expected), m.Fields)
+	}
+	for field, name := range expected {
+		if out, ok := m.Name(field); !ok || out != name {
+			t.Errorf("got %q from Name(%q), expected %q", out, field, name)
+		}
+	}
+	if _, ok := m.Name("APIKey"); ok {
+		t.Error("APIKey should be ignored")
+	}
+	if _, ok := m.Name("internal"); ok {
+		t.Error("unexported fields should be ignored")
+	}
+
+	// Name is shadowed by the outer field
+	mapping, _ := m.ByName("name")
+	if !reflect.DeepEqual(mapping.Index, []int{2}) {
+		t.Errorf("got index %v for name, expected [2]", mapping.Index)
+	}
+	mapping, _ = m.ByName("updated_by")
+	if !reflect.DeepEqual(mapping.Index, []int{1, 0}) {
+		t.Errorf("got index %v for updated_by, expected [1 0]", mapping.Index)
+	}
+
+	m, _ = NewTagFieldMapper("json", GoNaming).MapOf(fieldMapperUser{})
+	if name, _ := m.Name("UserID"); name != "userId" {
+		t.Errorf("got %q, expected %q", name, "userId")
+	}
+	if name, _ := m.Name("HTMLTitle"); name != "HTMLTitle" {
+		t.Errorf("got %q, expected %q", name, "HTMLTitle")
+	}
+}
+
+func TestFieldMapperCache(t *testing.T) {
+	fm := NewFieldMapper(SnakeCaseNaming)
+	m1, _ := fm.MapOf(fieldMapperUser{})
+	m2, _ := fm.MapOf(&fieldMapperUser{})
+	if m1 != m2 {
+		t.Error("FieldMap was not cached")
+	}
+
+	if _, err := fm.MapOf(1); err == nil {
+		t.Error("Expected an error mapping an int")
+	}
+	if _, err := fm.MapOf(nil); err == nil {
+		t.Error("Expected an error mapping nil")
+	}
+}
+
+type fieldMapperOrder struct {
+	ID    int
+	Code  string `db:"code"`
+	Total int    `db:"order_total"`
+}
+
+type fieldMapperInvoice struct {
+	ID    int
+	Code  string
+	Total int
+}
+
+type fieldMapperConflict struct {
+	fieldMapperOrder
+	fieldMapperInvoice
+	Note string
+}
+
+type fieldMapperWrapA struct{ fieldMapperBase }
+type fieldMapperWrapB struct{ fieldMapperBase }
+
+type fieldMapperDiamond struct {
+	fieldMapperWrapA
+	fieldMapperWrapB
+	Name string
+}
+
+func TestFieldMapperAmbiguous(t *testing.T) {
+	fm := NewTagFieldMapper("db", SnakeCaseNaming)
+	m, err := fm.MapOf(fieldMapperConflict{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Both IDs are dropped, the tagged Code wins and the Totals have different
+	// names, but the same Go name
+	expected := []FieldMapping{
+		{Field: "Code", Name: "code", Index: []int{0, 1}},
+		{Field: "Total", Name: "order_total", Index: []int{0, 2}},
+		{Field: "Total", Name: "total", Index: []int{1, 2}},
+		{Field: "Note", Name: "note", Index: []int{2}},
+	}
+	if !reflect.DeepEqual(m.Fields, expected) {
+		t.Errorf("got %+v, expected %+v", m.Fields, expected)
+	}
+	if name, ok := m.Name("ID"); ok {
+		t.Errorf("got %q for the ambiguous ID", name)
+	}
+	if name, ok := m.Name("Total"); ok {
+		t.Errorf("got %q for the ambiguous Total", name)
+	}
+	if mapping, _ := m.ByName("total"); !reflect.DeepEqual(mapping.Index, []int{1, 2}) {
+		t.Errorf("got %+v for total", mapping)
+	}
+
+	// The fields of a struct embedded twice at the same depth
+	m, _ = fm.MapOf(fieldMapperDiamond{})
+	expected = []FieldMapping{{Field: "Name", Name: "name", Index: []int{2}}}
+	if !reflect.DeepEqual(m.Fields, expected) {
+		t.Errorf("got %+v, expected %+v", m.Fields, expected)
+	}
+}
+
+func BenchmarkFieldMapperCached(b *testing.B) {
+	fm := NewTagFieldMapper("db", SnakeCaseNaming)
+	typ := reflect.TypeOf(fieldMapperUser{})
+	for i := 0; i < b.N; i++ {
+		_, _ = fm.Map(typ)
+	}
+}
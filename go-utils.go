@@ -1,6 +1,7 @@
 /*
-utils are currently just a wrapper on top of github/segmentio's extremely fast
+utils started out as a wrapper on top of github/segmentio's extremely fast
 Camelcase and Snakecase functions, with an added PascalCase.
+The case functions now share a Unicode aware word splitter, see Converter.
 
 Thank you @tj for switching to Go just before we did! ;)
 */
@@ -12,90 +13,153 @@ import (
 	"reflect"
 	"regexp"
 	"runtime"
+	"slices"
+	"sort"
 	"strconv"
 	"strings"
-
-	"github.com/segmentio/go-camelcase"
-	"github.com/segmentio/go-snakecase"
 )
 
+// Slug converts str to a lower cased, dash separated string, e.g. "sample-text"
 func Slug(str string) string {
-	return strings.Replace(snakecase.Snakecase(str), "_", "-", -1)
+	return DefaultConverter.KebabCase(str)
 }
 
+// UnCase converts str to a space separated sentence, e.g. "Sample text"
 func UnCase(str string) string {
-	str = strings.Replace(snakecase.Snakecase(str), "_", " ", -1)
-	str = strings.ToUpper(str[0:1]) + str[1:]
-	return str
+	words := DefaultConverter.Words(str)
+	if len(words) == 0 {
+		return ""
+	}
+	return titleFirst(strings.ToLower(strings.Join(words, " ")))
 }
 
+// SnakeCase converts str to snake_case using DefaultConverter
 func SnakeCase(str string) string {
-	return snakecase.Snakecase(str)
+	return DefaultConverter.SnakeCase(str)
 }
 
+// KebabCase converts str to kebab-case using DefaultConverter
 func KebabCase(str string) string {
-	return strings.Replace(snakecase.Snakecase(str), "_", "-", -1)
+	return DefaultConverter.KebabCase(str)
 }
 
+// CamelCase converts str to camelCase using DefaultConverter
 func CamelCase(str string) string {
-	return camelcase.Camelcase(str)
+	return DefaultConverter.CamelCase(str)
 }
 
+// PascalCase converts str to PascalCase using DefaultConverter
 func PascalCase(str string) string {
-	out := camelcase.Camelcase(str)
-	if len(out) > 0 {
-		out = strings.ToUpper(out[0:1]) + out[1:]
-	}
-	return out
+	return DefaultConverter.PascalCase(str)
 }
 
+// StringInSlice reports whether searchStr is in strs, see Contains
 func StringInSlice(searchStr string, strs []string) bool {
-	for _, str := range strs {
-		if searchStr == str {
-			return true
-		}
-	}
-	return false
+	return Contains(strs, searchStr)
 }
 
+// UniqueInts returns the distinct ints of arr in the order they first occur,
+// see UniqueValues
 func UniqueInts(arr []int) (unique []int) {
-	tmpMap := map[int]bool{}
-	for i := 0; i < len(arr); i++ {
-		tmpMap[arr[i]] = true
-	}
-	for val := range tmpMap {
-		unique = append(unique, val)
-	}
-	return
+	return UniqueValues(arr)
 }
 
+// UniqueStrings returns the distinct strings of arr in the order they first
+// occur, see UniqueValues
 func UniqueStrings(arr []string) (unique []string) {
-	tmpMap := map[string]bool{}
-	for i := 0; i < len(arr); i++ {
-		tmpMap[arr[i]] = true
-	}
-	for val := range tmpMap {
-		unique = append(unique, val)
-	}
-	return
+	return UniqueValues(arr)
 }
 
+// Unique returns the distinct values of the slice arr as a slice of the same
+// type, in the order they first occur
+// See UniqueValues for a type safe version.
 func Unique(arr interface{}) (unique interface{}, err error) {
+	return UniqueWithOrder(arr, FirstOccurrence)
+}
+
+// UniqueOrder is the order of the values returned by UniqueWithOrder
+type UniqueOrder int
+
+const (
+	// FirstOccurrence keeps the values in the order they first occur
+	FirstOccurrence UniqueOrder = iota
+	// LastOccurrence keeps the values in the order they last occur
+	LastOccurrence
+	// SortedOrder sorts the values, which must be numbers or strings
+	SortedOrder
+)
+
+// UniqueWithOrder returns the distinct values of the slice arr as a slice of
+// the same type, in the given order
+// Values that can't be map keys, like slices, maps and structs or interfaces
+// holding them, are compared with reflect.DeepEqual instead.
+func UniqueWithOrder(arr interface{}, order UniqueOrder) (unique interface{}, err error) {
+	return uniqueReflect(arr, order, nil)
+}
+
+// UniqueWithKey returns the values of the slice arr with distinct keys as a
+// slice of the same type, keeping the first value for each key
+// key is called with each value and should return something comparable, e.g.
+// an ID or a hash. Keys that aren't are compared with reflect.DeepEqual.
+func UniqueWithKey(arr interface{}, key func(interface{}) interface{}) (unique interface{}, err error) {
+	return uniqueReflect(arr, FirstOccurrence, key)
+}
+
+func uniqueReflect(arr interface{}, order UniqueOrder, key func(interface{}) interface{}) (unique interface{}, err error) {
+	if arr == nil {
+		return nil, errors.New("Not a slice, got nil")
+	}
 	arrType := reflect.TypeOf(arr)
 	arrValue := reflect.ValueOf(arr)
 
-	if arrType.Kind().String() != "slice" {
+	if arrType.Kind() != reflect.Slice {
 		return nil, errors.New("Not a slice")
 	}
+	if order < FirstOccurrence || order > SortedOrder {
+		return nil, errors.New("Unknown order " + strconv.Itoa(int(order)))
+	}
+
+	// Only check each value if some might not be usable as map keys
+	checkHashable := key != nil || !isHashable(arrType.Elem())
 
+	// Find the indexes to keep, backwards for LastOccurrence
+	n := arrValue.Len()
 	tmpMap := map[interface{}]bool{}
-	for i := 0; i < arrValue.Len(); i++ {
-		tmpMap[arrValue.Index(i).Interface()] = true
+	var unhashable []interface{}
+	var keep []int
+	for j := 0; j < n; j++ {
+		i := j
+		if order == LastOccurrence {
+			i = n - 1 - j
+		}
+		val := arrValue.Index(i).Interface()
+		if key != nil {
+			val = key(val)
+		}
+
+		if checkHashable && val != nil && !reflect.ValueOf(val).Comparable() {
+			if !containsDeepEqual(unhashable, val) {
+				unhashable = append(unhashable, val)
+				keep = append(keep, i)
+			}
+		} else if !tmpMap[val] {
+			tmpMap[val] = true
+			keep = append(keep, i)
+		}
+	}
+	if order == LastOccurrence {
+		slices.Reverse(keep)
+	}
+
+	newArr := reflect.MakeSlice(arrType, len(keep), len(keep))
+	for j, i := range keep {
+		newArr.Index(j).Set(arrValue.Index(i))
 	}
 
-	newArr := reflect.MakeSlice(arrType, 0, arrValue.Len())
-	for val := range tmpMap {
-		newArr = reflect.Append(newArr, reflect.ValueOf(val))
+	if order == SortedOrder {
+		if err = sortValues(newArr); err != nil {
+			return nil, err
+		}
 	}
 
 	unique = newArr.Interface()
@@ -103,6 +167,55 @@ func Unique(arr interface{}) (unique interface{}, err error) {
 	return
 }
 
+// isHashable reports whether all values of typ can be used as map keys
+// Interfaces can hold anything, so they don't count.
+func isHashable(typ reflect.Type) bool {
+	switch typ.Kind() {
+	case reflect.Interface:
+		return false
+	case reflect.Array:
+		return isHashable(typ.Elem())
+	case reflect.Struct:
+		for i := 0; i < typ.NumField(); i++ {
+			if !isHashable(typ.Field(i).Type) {
+				return false
+			}
+		}
+		return true
+	}
+	return typ.Comparable()
+}
+
+func containsDeepEqual(vals []interface{}, val interface{}) bool {
+	for _, other := range vals {
+		if reflect.DeepEqual(other, val) {
+			return true
+		}
+	}
+	return false
+}
+
+// sortValues sorts a slice of numbers or strings in place
+func sortValues(arr reflect.Value) error {
+	var less func(a, b reflect.Value) bool
+	switch arr.Type().Elem().Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		less = func(a, b reflect.Value) bool { return a.Int() < b.Int() }
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		less = func(a, b reflect.Value) bool { return a.Uint() < b.Uint() }
+	case reflect.Float32, reflect.Float64:
+		less = func(a, b reflect.Value) bool { return a.Float() < b.Float() }
+	case reflect.String:
+		less = func(a, b reflect.Value) bool { return a.String() < b.String() }
+	default:
+		return errors.New("Can't sort values of type " + arr.Type().Elem().String())
+	}
+	sort.Slice(arr.Interface(), func(i, j int) bool {
+		return less(arr.Index(i), arr.Index(j))
+	})
+	return nil
+}
+
 // InterfaceToReflect helps ensure the reflect value is in an editable state
 // It will check the type and get the correct reference if possible
 // TODO(morphar) Make some tests
@@ -7,6 +7,8 @@ Thank you @tj for switching to Go just before we did! ;)
 package utils
 
 import (
+	"fmt"
+	"reflect"
 	"strconv"
 	"testing"
 )
@@ -34,7 +36,10 @@ func TestSlug(t *testing.T) {
 		{"something.com", "something-com"},
 		{"$something%", "something"},
 		{"something.com", "something-com"},
-		{"•¶§ƒ˚foo˙∆˚¬", "foo"},
+		{"•¶§ƒ˚foo˙∆˚¬", "ƒ-foo"},
+		{"Größe Straße", "größe-straße"},
+		{"ærøÅbent", "ærø-åbent"},
+		{"日本語 テキスト", "日本語-テキスト"},
 	}
 
 	for _, sample := range samples {
@@ -63,7 +68,13 @@ func TestUnCase(t *testing.T) {
 		{"something.com", "Something com"},
 		{"$something%", "Something"},
 		{"something.com", "Something com"},
-		{"•¶§ƒ˚foo˙∆˚¬", "Foo"},
+		{"•¶§ƒ˚foo˙∆˚¬", "Ƒ foo"},
+		{"größeStraße", "Größe straße"},
+		{"ærø_åbent", "Ærø åbent"},
+		{"ǆungla", "ǅungla"},
+		{"日本語_テキスト", "日本語 テキスト"},
+		{"", ""},
+		{"$%&", ""},
 	}
 
 	for _, sample := range samples {
@@ -92,7 +103,13 @@ func TestSnakeCase(t *testing.T) {
 		{"something.com", "something_com"},
 		{"$something%", "something"},
 		{"something.com", "something_com"},
-		{"•¶§ƒ˚foo˙∆˚¬", "foo"},
+		{"•¶§ƒ˚foo˙∆˚¬", "ƒ_foo"},
+		{"GrößeStraße", "größe_straße"},
+		{"ÆrøÅbent", "ærø_åbent"},
+		{"ÆRØÅbent", "ærø_åbent"},
+		{"Cafe\u0301Noir", "cafe\u0301_noir"},
+		{"日本語 テキスト", "日本語_テキスト"},
+		{"ЖёлтыйЦвет", "жёлтый_цвет"},
 	}
 
 	for _, sample := range samples {
@@ -121,7 +138,9 @@ func TestKebabCase(t *testing.T) {
 		{"something.com", "something-com"},
 		{"$something%", "something"},
 		{"something.com", "something-com"},
-		{"•¶§ƒ˚foo˙∆˚¬", "foo"},
+		{"•¶§ƒ˚foo˙∆˚¬", "ƒ-foo"},
+		{"GrößeStraße", "größe-straße"},
+		{"ÆrøÅbent", "ærø-åbent"},
 	}
 
 	for _, sample := range samples {
@@ -150,7 +169,10 @@ func TestCamelCase(t *testing.T) {
 		{"something.com", "somethingCom"},
 		{"$something%", "something"},
 		{"something.com", "somethingCom"},
-		{"•¶§ƒ˚foo˙∆˚¬", "foo"},
+		{"•¶§ƒ˚foo˙∆˚¬", "ƒFoo"},
+		{"größe_straße", "größeStraße"},
+		{"ærø åbent", "ærøÅbent"},
+		{"жёлтый цвет", "жёлтыйЦвет"},
 	}
 
 	for _, sample := range samples {
@@ -179,7 +201,11 @@ func TestPascalCase(t *testing.T) {
 		{"something.com", "SomethingCom"},
 		{"$something%", "Something"},
 		{"something.com", "SomethingCom"},
-		{"•¶§ƒ˚foo˙∆˚¬", "Foo"},
+		{"•¶§ƒ˚foo˙∆˚¬", "ƑFoo"},
+		{"größe_straße", "GrößeStraße"},
+		{"ærø åbent", "ÆrøÅbent"},
+		{"ǆungla", "ǅungla"},
+		{"日本語 テキスト", "日本語テキスト"},
 	}
 
 	for _, sample := range samples {
@@ -230,6 +256,103 @@ func TestUnique(t *testing.T) {
 	}
 }
 
+func TestUniqueWithOrder(t *testing.T) {
+	ints := []int{3, 1, 3, 2, 1}
+	var tests = []struct {
+		order UniqueOrder
+		out   []int
+	}{
+		{FirstOccurrence, []int{3, 1, 2}},
+		{LastOccurrence, []int{3, 2, 1}},
+		{SortedOrder, []int{1, 2, 3}},
+	}
+	for _, test := range tests {
+		out, err := UniqueWithOrder(ints, test.order)
+		if err != nil || !reflect.DeepEqual(out, test.out) {
+			t.Errorf("got %v, %v from order %d, expected %v", out, err, test.order, test.out)
+		}
+	}
+
+	out, _ := UniqueWithOrder([]string{"b", "c", "a", "c"}, SortedOrder)
+	if !reflect.DeepEqual(out, []string{"a", "b", "c"}) {
+		t.Errorf("got %v", out)
+	}
+	out, _ = UniqueWithOrder([]float64{2.5, -1, 2.5}, SortedOrder)
+	if !reflect.DeepEqual(out, []float64{-1, 2.5}) {
+		t.Errorf("got %v", out)
+	}
+
+	if _, err := UniqueWithOrder([]bool{true}, SortedOrder); err == nil {
+		t.Error("Expected an error sorting bools")
+	}
+	if _, err := UniqueWithOrder(ints, UniqueOrder(7)); err == nil {
+		t.Error("Expected an error for an unknown order")
+	}
+
+	// The same order on every run
+	uniqueInts := UniqueInts([]int{5, 4, 5, 3, 4})
+	uniqueStrings := UniqueStrings([]string{"e", "d", "e", "c"})
+	if !reflect.DeepEqual(uniqueInts, []int{5, 4, 3}) || !reflect.DeepEqual(uniqueStrings, []string{"e", "d", "c"}) {
+		t.Errorf("got %v and %v", uniqueInts, uniqueStrings)
+	}
+}
+
+func TestUniqueUnhashable(t *testing.T) {
+	type tagged struct {
+		Name string
+		Tags []string
+	}
+
+	var tests = []struct {
+		in, out interface{}
+	}{
+		{[][]int{{1, 2}, {3}, {1, 2}, nil, {3}, nil}, [][]int{{1, 2}, {3}, nil}},
+		{[]map[string]int{{"a": 1}, {"a": 1}, {"b": 2}}, []map[string]int{{"a": 1}, {"b": 2}}},
+		{[]tagged{{"a", []string{"x"}}, {"b", nil}, {"a", []string{"x"}}}, []tagged{{"a", []string{"x"}}, {"b", nil}}},
+		{[]interface{}{1, []int{1}, "a", []int{1}, 1, nil, nil}, []interface{}{1, []int{1}, "a", nil}},
+		{[]interface{}{}, []interface{}{}},
+	}
+
+	for _, test := range tests {
+		out, err := Unique(test.in)
+		if err != nil || !reflect.DeepEqual(out, test.out) {
+			t.Errorf("got %v, %v from %v, expected %v", out, err, test.in, test.out)
+		}
+	}
+
+	out, err := UniqueWithOrder([][]int{{1}, {2}, {1}}, LastOccurrence)
+	if err != nil || !reflect.DeepEqual(out, [][]int{{2}, {1}}) {
+		t.Errorf("got %v, %v", out, err)
+	}
+
+	if _, err := Unique(nil); err == nil || err.Error() != "Not a slice, got nil" {
+		t.Errorf("got %v from nil", err)
+	}
+	if _, err := Unique(1); err == nil {
+		t.Error("Expected an error for a non-slice")
+	}
+}
+
+func TestUniqueWithKey(t *testing.T) {
+	type user struct {
+		ID    int
+		Email string
+		Roles []string
+	}
+	users := []user{{1, "a@x", nil}, {2, "b@x", []string{"admin"}}, {1, "a@y", nil}}
+
+	out, err := UniqueWithKey(users, func(val interface{}) interface{} { return val.(user).ID })
+	if err != nil || !reflect.DeepEqual(out, []user{users[0], users[1]}) {
+		t.Errorf("got %v, %v", out, err)
+	}
+
+	// Keys that aren't comparable are compared deeply
+	out, err = UniqueWithKey(users, func(val interface{}) interface{} { return val.(user).Roles })
+	if err != nil || !reflect.DeepEqual(out, []user{users[0], users[1]}) {
+		t.Errorf("got %v, %v", out, err)
+	}
+}
+
 func BenchmarkSnakeCase(b *testing.B) {
 	for i := 0; i < b.N; i++ {
 		_ = SnakeCase("some sample text here_noething:too$amazing")
@@ -306,6 +429,34 @@ func BenchmarkUniqueIntsLargeManyUniques(b *testing.B) {
 	}
 }
 
+// BenchmarkUniqueIntsParallel compares UniqueInts and UniqueIntsParallel on
+// growing slices where 10% of the values are unique, to show where the
+// parallel version starts to win, e.g. go test -bench UniqueIntsParallel -cpu 1,4,8
+func BenchmarkUniqueIntsParallel(b *testing.B) {
+	for _, size := range []int{10000, 100000, 1000000, 10000000} {
+		ints := make([]int, size)
+		for i := range ints {
+			ints[i] = i % (size / 10)
+		}
+
+		b.Run(fmt.Sprintf("size=%d/serial", size), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				_ = UniqueInts(ints)
+			}
+		})
+		b.Run(fmt.Sprintf("size=%d/parallel", size), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				_ = UniqueIntsParallel(ints, false)
+			}
+		})
+		b.Run(fmt.Sprintf("size=%d/parallel-ordered", size), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				_ = UniqueIntsParallel(ints, true)
+			}
+		})
+	}
+}
+
 func BenchmarkUniqueStringsSmallFewUniques(b *testing.B) {
 	var strings []string
 	for i := 0; i < 100; i++ {
@@ -358,6 +509,34 @@ func BenchmarkUniqueStringsLargeManyUniques(b *testing.B) {
 	}
 }
 
+// BenchmarkUniqueStringsParallel compares UniqueStrings and UniqueStringsParallel on
+// growing slices where 10% of the values are unique, to show where the
+// parallel version starts to win, e.g. go test -bench UniqueStringsParallel -cpu 1,4,8
+func BenchmarkUniqueStringsParallel(b *testing.B) {
+	for _, size := range []int{10000, 100000, 1000000, 10000000} {
+		strs := make([]string, size)
+		for i := range strs {
+			strs[i] = strconv.Itoa(i % (size / 10))
+		}
+
+		b.Run(fmt.Sprintf("size=%d/serial", size), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				_ = UniqueStrings(strs)
+			}
+		})
+		b.Run(fmt.Sprintf("size=%d/parallel", size), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				_ = UniqueStringsParallel(strs, false)
+			}
+		})
+		b.Run(fmt.Sprintf("size=%d/parallel-ordered", size), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				_ = UniqueStringsParallel(strs, true)
+			}
+		})
+	}
+}
+
 func BenchmarkUniqueSmallIntsFewUniques(b *testing.B) {
 	var ints []int
 	for i := 0; i < 100; i++ {
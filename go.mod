@@ -0,0 +1,3 @@
+module github.com/nosco/go-utils
+
+go 1.23
@@ -0,0 +1,111 @@
+package utils
+
+import (
+	"hash/maphash"
+	"runtime"
+	"sync"
+)
+
+// UniqueValuesParallel returns the distinct values of s using GOMAXPROCS
+// goroutines, for slices of millions of values
+// Values are partitioned by hash, which must return the same hash for equal
+// values, and each partition is deduplicated on its own. With keepOrder the
+// values are in the order they first occur like UniqueValues, otherwise the
+// order is unspecified, which is a bit faster. For smaller slices UniqueValues
+// is faster, run BenchmarkUniqueIntsParallel with -cpu to find the crossover
+// on a given machine.
+func UniqueValuesParallel[T comparable](s []T, hash func(T) uint64, keepOrder bool) []T {
+	workers := runtime.GOMAXPROCS(0)
+	if workers == 1 || len(s) < 2*workers {
+		return UniqueValues(s)
+	}
+
+	// Split s into a chunk per worker, and the indices of each chunk into a
+	// shard per worker by hash
+	chunkSize := (len(s) + workers - 1) / workers
+	shards := make([][][]int, workers) // Chunk, shard, index
+	parallel(workers, func(chunk int) {
+		start, end := min(chunk*chunkSize, len(s)), min((chunk+1)*chunkSize, len(s))
+		shards[chunk] = make([][]int, workers)
+		for i := start; i < end; i++ {
+			shard := hash(s[i]) % uint64(workers)
+			shards[chunk][shard] = append(shards[chunk][shard], i)
+		}
+	})
+
+	// Deduplicate each shard, visiting the chunks in order so the first
+	// occurrence of each value is kept
+	var first []bool
+	if keepOrder {
+		first = make([]bool, len(s))
+	}
+	unique := make([][]T, workers)
+	parallel(workers, func(shard int) {
+		seen := map[T]struct{}{}
+		for chunk := range shards {
+			for _, i := range shards[chunk][shard] {
+				if _, ok := seen[s[i]]; ok {
+					continue
+				}
+				seen[s[i]] = struct{}{}
+				if keepOrder {
+					first[i] = true // Each index is set by one shard only
+				} else {
+					unique[shard] = append(unique[shard], s[i])
+				}
+			}
+		}
+	})
+
+	if keepOrder {
+		parallel(workers, func(chunk int) {
+			start, end := min(chunk*chunkSize, len(s)), min((chunk+1)*chunkSize, len(s))
+			for i := start; i < end; i++ {
+				if first[i] {
+					unique[chunk] = append(unique[chunk], s[i])
+				}
+			}
+		})
+	}
+	return Flatten(unique)
+}
+
+// UniqueIntsParallel returns the distinct ints of arr using GOMAXPROCS
+// goroutines, see UniqueValuesParallel
+func UniqueIntsParallel(arr []int, keepOrder bool) []int {
+	return UniqueValuesParallel(arr, hashInt, keepOrder)
+}
+
+// UniqueStringsParallel returns the distinct strings of arr using GOMAXPROCS
+// goroutines, see UniqueValuesParallel
+func UniqueStringsParallel(arr []string, keepOrder bool) []string {
+	return UniqueValuesParallel(arr, hashString, keepOrder)
+}
+
+var hashSeed = maphash.MakeSeed()
+
+func hashString(str string) uint64 {
+	return maphash.String(hashSeed, str)
+}
+
+// hashInt mixes the bits of i, so ints with a common stride still spread
+// evenly over the shards (the splitmix64 finalizer)
+func hashInt(i int) uint64 {
+	h := uint64(i)
+	h = (h ^ h>>30) * 0xbf58476d1ce4e5b9
+	h = (h ^ h>>27) * 0x94d049bb133111eb
+	return h ^ h>>31
+}
+
+// parallel calls f(0) to f(n-1) in n goroutines and waits for them to return
+func parallel(n int, f func(i int)) {
+	var wg sync.WaitGroup
+	wg.Add(n)
+	for i := 0; i < n; i++ {
+		go func() {
+			defer wg.Done()
+			f(i)
+		}()
+	}
+	wg.Wait()
+}
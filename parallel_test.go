@@ -0,0 +1,51 @@
+package utils
+
+import (
+	"reflect"
+	"runtime"
+	"slices"
+	"strconv"
+	"testing"
+)
+
+func TestUniqueValuesParallel(t *testing.T) {
+	// Use several workers even on a single CPU
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(4))
+
+	var tests = [][]int{
+		nil,
+		{1},
+		{3, 1, 3, 2, 1},
+		make([]int, 1000),
+	}
+	var ints []int
+	for i := 0; i < 100000; i++ {
+		ints = append(ints, (i*7919)%10007)
+	}
+	tests = append(tests, ints)
+
+	for _, test := range tests {
+		expected := UniqueInts(test)
+		if out := UniqueIntsParallel(test, true); !reflect.DeepEqual(out, expected) {
+			t.Errorf("got %d ordered values from %d, expected %d", len(out), len(test), len(expected))
+		}
+
+		out := UniqueIntsParallel(test, false)
+		slices.Sort(out)
+		if sorted := UniqueSorted(test); !slices.Equal(out, sorted) {
+			t.Errorf("got %d values from %d, expected %d", len(out), len(test), len(sorted))
+		}
+	}
+}
+
+func TestUniqueStringsParallel(t *testing.T) {
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(3))
+
+	var strs []string
+	for i := 0; i < 10000; i++ {
+		strs = append(strs, strconv.Itoa(i%777))
+	}
+	if out := UniqueStringsParallel(strs, true); !reflect.DeepEqual(out, UniqueStrings(strs)) {
+		t.Errorf("got %d values, expected 777", len(out))
+	}
+}
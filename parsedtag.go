@@ -0,0 +1,231 @@
+package utils
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// TagEntry is a single key:"value" pair of a struct tag
+type TagEntry struct {
+	Space string // Whitespace written before the entry
+	Key   string
+	Raw   string // The quoted value exactly as written, e.g. `"id,omitempty"`
+}
+
+// Value returns the unquoted value of the entry
+func (e TagEntry) Value() (string, error) {
+	return strconv.Unquote(e.Raw)
+}
+
+// ParsedTag is a struct tag split into its entries
+// It follows the lexical rules of reflect.StructTag and turns back into the
+// exact same TagString it was parsed from.
+type ParsedTag struct {
+	Entries []TagEntry
+
+	// Rest is whatever follows the last entry, normally nothing or trailing
+	// whitespace, but it keeps anything that could not be parsed as well
+	Rest string
+}
+
+// ParseTag splits tag into its entries
+// If the tag is malformed, the entries up to the error are returned along with
+// the error, and the remainder of the tag is kept in Rest.
+func ParseTag(tag TagString) (p *ParsedTag, err error) {
+	p = &ParsedTag{}
+	str := string(tag)
+	offset := 0
+
+	for str != "" {
+		// Skip leading space
+		i := 0
+		for i < len(str) && str[i] == ' ' {
+			i++
+		}
+		if i == len(str) {
+			break
+		}
+
+		// Scan to colon. A space, a quote or a control character is a syntax
+		// error, just like in reflect.StructTag.Lookup
+		j := i
+		for j < len(str) && str[j] > ' ' && str[j] != ':' && str[j] != '"' && str[j] != 0x7f {
+			j++
+		}
+		if j == i || j+1 >= len(str) || str[j] != ':' || str[j+1] != '"' {
+			err = syntaxError(str, i, j, offset)
+			break
+		}
+
+		// Scan quoted string to find value
+		k := j + 2
+		for k < len(str) && str[k] != '"' {
+			if str[k] == '\\' {
+				k++
+			}
+			k++
+		}
+		if k >= len(str) {
+			err = &TagError{Offset: offset + j + 1, Key: str[i:j], Problem: errTagValueSyntax}
+			break
+		}
+
+		p.Entries = append(p.Entries, TagEntry{Space: str[:i], Key: str[i:j], Raw: str[j+1 : k+1]})
+		offset += k + 1
+		str = str[k+1:]
+	}
+
+	p.Rest = str
+	return
+}
+
+// syntaxError describes why str[i:] doesn't start with key:"value", in the
+// same order of precedence as go vet. j is the end of the key.
+func syntaxError(str string, i, j, offset int) *TagError {
+	switch {
+	case j == i:
+		return &TagError{Offset: offset + i, Problem: errTagKeySyntax}
+	case j+1 >= len(str) || str[j] != ':':
+		return &TagError{Offset: offset + i, Key: str[i:j], Problem: errTagSyntax}
+	}
+	return &TagError{Offset: offset + j + 1, Key: str[i:j], Problem: errTagValueSyntax}
+}
+
+// Parse splits the tag into its entries, see ParseTag
+func (tag TagString) Parse() (*ParsedTag, error) {
+	return ParseTag(tag)
+}
+
+// TagString joins the entries into a tag again
+func (p *ParsedTag) TagString() TagString {
+	var buf strings.Builder
+	for _, e := range p.Entries {
+		buf.WriteString(e.Space)
+		buf.WriteString(e.Key)
+		buf.WriteByte(':')
+		buf.WriteString(e.Raw)
+	}
+	buf.WriteString(p.Rest)
+	return TagString(buf.String())
+}
+
+func (p *ParsedTag) String() string {
+	return string(p.TagString())
+}
+
+// Lookup returns the value of the first entry identified by key
+func (p *ParsedTag) Lookup(key string) (val string, ok bool) {
+	for _, e := range p.Entries {
+		if e.Key == key {
+			val, err := e.Value()
+			return val, err == nil
+		}
+	}
+	return
+}
+
+// Get returns the value of the first entry identified by key
+func (p *ParsedTag) Get(key string) string {
+	val, _ := p.Lookup(key)
+	return val
+}
+
+// Set ONLY sets the value of entries identified by key, if they already exist
+// Returns true if an entry was found and changed
+func (p *ParsedTag) Set(key, val string) (success bool) {
+	for i := range p.Entries {
+		if p.Entries[i].Key == key {
+			p.Entries[i].Raw = strconv.Quote(val)
+			success = true
+		}
+	}
+	return
+}
+
+// Add sets the value of the entries identified by key, or appends a new entry
+// if none exists
+func (p *ParsedTag) Add(key, val string) {
+	if p.Set(key, val) {
+		return
+	}
+	space := " "
+	if len(p.Entries) == 0 {
+		space = ""
+	}
+	if strings.TrimSpace(p.Rest) == "" {
+		p.Rest = ""
+	}
+	p.Entries = append(p.Entries, TagEntry{Space: space, Key: key, Raw: strconv.Quote(val)})
+}
+
+// Remove removes all entries identified by key
+// Returns true if any entries were removed
+func (p *ParsedTag) Remove(key string) (success bool) {
+	n := 0
+	space := ""
+	for _, e := range p.Entries {
+		if e.Key == key {
+			if n == 0 && !success {
+				// Keep the leading space of the tag
+				space = e.Space
+			}
+			success = true
+			continue
+		}
+		if n == 0 && success {
+			e.Space = space
+		}
+		p.Entries[n] = e
+		n++
+	}
+	p.Entries = p.Entries[:n]
+	if n == 0 {
+		p.Rest = strings.TrimLeft(p.Rest, " ")
+	}
+	return
+}
+
+// Sort sorts the entries alphabetically by key, except favor the json tag
+// The entries are separated by single spaces afterwards.
+func (p *ParsedTag) Sort() {
+	sort.Stable(byKey(p.Entries))
+	if strings.TrimSpace(p.Rest) == "" {
+		p.Rest = ""
+	}
+	for i := range p.Entries {
+		if i == 0 {
+			p.Entries[i].Space = ""
+		} else {
+			p.Entries[i].Space = " "
+		}
+	}
+}
+
+type byKey []TagEntry
+
+func (a byKey) Len() int      { return len(a) }
+func (a byKey) Swap(i, j int) { a[i], a[j] = a[j], a[i] }
+func (a byKey) Less(i, j int) bool {
+	// Favor json tags as they are what tags are generally used for
+	if a[i].Key == "json" {
+		return a[j].Key != "json"
+	}
+	if a[j].Key == "json" {
+		return false
+	}
+	return a[i].Key < a[j].Key
+}
+
+// validTagKey reports whether key can be used as a tag key
+func validTagKey(key string) bool {
+	if key == "" {
+		return false
+	}
+	for i := 0; i < len(key); i++ {
+		if key[i] <= ' ' || key[i] == ':' || key[i] == '"' || key[i] == 0x7f {
+			return false
+		}
+	}
+	return true
+}
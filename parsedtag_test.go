@@ -0,0 +1,143 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseTag(t *testing.T) {
+	var tests = []struct {
+		in      TagString
+		entries []TagEntry
+		rest    string
+		err     bool
+	}{
+		{``, nil, "", false},
+		{`json:"id"`, []TagEntry{{"", "json", `"id"`}}, "", false},
+		{`  json:"id,omitempty"   db:"id" `, []TagEntry{{"  ", "json", `"id,omitempty"`}, {"   ", "db", `"id"`}}, " ", false},
+		{`a:"x\"y" b:"\\"`, []TagEntry{{"", "a", `"x\"y"`}, {" ", "b", `"\\"`}}, "", false},
+		{`a:"1"b:"2"`, []TagEntry{{"", "a", `"1"`}, {"", "b", `"2"`}}, "", false},
+		{`a:"1" b c:"3"`, []TagEntry{{"", "a", `"1"`}}, ` b c:"3"`, true},
+		{`a:"1" b:"2`, []TagEntry{{"", "a", `"1"`}}, ` b:"2`, true},
+		{`a:1`, nil, `a:1`, true},
+		{`:"1"`, nil, `:"1"`, true},
+	}
+
+	for _, tt := range tests {
+		p, err := ParseTag(tt.in)
+		if (err != nil) != tt.err {
+			t.Errorf("got error %v parsing %s, expected error: %v", err, tt.in, tt.err)
+		}
+		if !reflect.DeepEqual(p.Entries, tt.entries) || p.Rest != tt.rest {
+			t.Errorf("got %+v and rest %q parsing %s, expected %+v and rest %q", p.Entries, p.Rest, tt.in, tt.entries, tt.rest)
+		}
+		if out := p.TagString(); out != tt.in {
+			t.Errorf("got %s back from %s", out, tt.in)
+		}
+	}
+}
+
+func TestParsedTagValues(t *testing.T) {
+	p, _ := ParseTag(`a:"x\"y" b:"" c:"\q"`)
+	if val, ok := p.Lookup("a"); !ok || val != `x"y` {
+		t.Errorf("got %q, %v looking up a, expected %q", val, ok, `x"y`)
+	}
+	if val, ok := p.Lookup("b"); !ok || val != "" {
+		t.Errorf("got %q, %v looking up b", val, ok)
+	}
+	if _, ok := p.Lookup("c"); ok {
+		t.Error("Lookup of c with an invalid escape should fail")
+	}
+	if _, ok := p.Lookup("d"); ok {
+		t.Error("Lookup found non-existing d")
+	}
+	if p.Get("a") != reflect.StructTag(`a:"x\"y"`).Get("a") {
+		t.Error("Get doesn't match reflect.StructTag.Get")
+	}
+}
+
+func TestParsedTagEdit(t *testing.T) {
+	p, _ := ParseTag(`  json:"id"  xml:"say \"hi\""  db:"id" `)
+
+	if !p.Set("xml", `say "bye"`) {
+		t.Error("Unable to Set xml")
+	}
+	if p.Set("yaml", "id") {
+		t.Error("Set created yaml")
+	}
+	p.Add("yaml", "id")
+	if out := p.TagString(); out != `  json:"id"  xml:"say \"bye\""  db:"id" yaml:"id"` {
+		t.Errorf("got %s after Set and Add", out)
+	}
+
+	if !p.Remove("json") {
+		t.Error("Unable to Remove json")
+	}
+	if p.Remove("json") {
+		t.Error("Removed json twice")
+	}
+	if out := p.TagString(); out != `  xml:"say \"bye\""  db:"id" yaml:"id"` {
+		t.Errorf("got %s after Remove", out)
+	}
+
+	p.Sort()
+	if out := p.TagString(); out != `db:"id" xml:"say \"bye\"" yaml:"id"` {
+		t.Errorf("got %s after Sort", out)
+	}
+
+	p.Remove("db")
+	p.Remove("xml")
+	p.Remove("yaml")
+	if out := p.TagString(); out != "" {
+		t.Errorf("got %s after removing everything", out)
+	}
+}
+
+func TestTagStringEscapedQuotes(t *testing.T) {
+	var testTag TagString = `xml:"a \"quoted\" value" json:"id"`
+	testTag.Sort()
+	if testTag != `json:"id" xml:"a \"quoted\" value"` {
+		t.Errorf("Sort mangled escaped quotes, got %s", testTag)
+	}
+
+	testTag.Set("xml", `"new"`)
+	if testTag.Get("xml") != `"new"` {
+		t.Errorf("got %q after Set", testTag.Get("xml"))
+	}
+
+	if err := testTag.Remove("xml"); err != nil || testTag != `json:"id"` {
+		t.Errorf("got %s, %v after Remove", testTag, err)
+	}
+}
+
+// Keys used to be put into regular expressions unescaped
+func TestTagStringKeyInjection(t *testing.T) {
+	var testTag TagString = `var1:"val1" var2:"val2"`
+	if testTag.Set("var.", "x") || testTag.Set(`var1:"val1" var2`, "x") {
+		t.Error("Set matched a key it shouldn't")
+	}
+	_ = testTag.Remove("var.")
+	if testTag != `var1:"val1" var2:"val2"` {
+		t.Errorf("Remove removed a key it shouldn't, got %s", testTag)
+	}
+	if err := testTag.Remove("bad key"); err == nil {
+		t.Error("Expected an error removing an invalid key")
+	}
+	testTag.Add("bad key", "x")
+	if testTag != `var1:"val1" var2:"val2"` {
+		t.Errorf("Add added an invalid key, got %s", testTag)
+	}
+
+	testTag = `var1:"val1" var2:"val2"`
+	_ = testTag.Remove("var1")
+	if testTag != `var2:"val2"` {
+		t.Errorf("got %s after removing the first tag", testTag)
+	}
+}
+
+func BenchmarkParseTag(b *testing.B) {
+	var testTag TagString = `var1:"val1" var2:"val2" var3:"val3" var4:"val4" var5:"val5"`
+	for i := 0; i < b.N; i++ {
+		_, _ = ParseTag(testTag)
+	}
+}
@@ -0,0 +1,252 @@
+package utils
+
+import (
+	"errors"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// GenerateProto writes a .proto file describing the generated protobuf struct
+// types and the messages they reference
+// The syntax is proto3 if the fields are tagged proto3, and proto2 otherwise.
+// Messages are named after their Go types. If pkg is given, it is declared as
+// the package and stripped from enum references in the same package.
+// Enums are only referenced, as their values can't be found by reflection.
+func GenerateProto(w io.Writer, pkg string, types ...reflect.Type) error {
+	var descs []*MessageDescriptor
+	seen := map[reflect.Type]bool{}
+	names := map[string]reflect.Type{}
+
+	queue := append([]reflect.Type{}, types...)
+	for len(queue) > 0 {
+		typ := queue[0]
+		queue = queue[1:]
+		for typ != nil && typ.Kind() == reflect.Ptr {
+			typ = typ.Elem()
+		}
+		if seen[typ] {
+			continue
+		}
+		seen[typ] = true
+
+		desc, err := DescribeMessage(typ)
+		if err != nil {
+			return err
+		}
+		if desc.Name == "" {
+			return errors.New("Anonymous struct " + typ.String() + " can't be a message")
+		}
+		if other, ok := names[desc.Name]; ok {
+			return errors.New("Message name " + desc.Name + " is used by both " + other.String() + " and " + typ.String())
+		}
+		names[desc.Name] = typ
+		descs = append(descs, desc)
+
+		for _, field := range desc.Fields {
+			if field.Message != nil {
+				queue = append(queue, field.Message)
+			}
+		}
+	}
+
+	proto3 := false
+	for _, desc := range descs {
+		proto3 = proto3 || desc.Proto3
+	}
+	for _, desc := range descs {
+		for _, field := range desc.Fields {
+			if field.Info.Proto3 != proto3 {
+				return errors.New("Messages mix proto2 and proto3 fields, see " + desc.Name + "." + field.GoName)
+			}
+		}
+	}
+
+	var buf strings.Builder
+	if proto3 {
+		buf.WriteString("syntax = \"proto3\";\n")
+	} else {
+		buf.WriteString("syntax = \"proto2\";\n")
+	}
+	if pkg != "" {
+		buf.WriteString("\npackage " + pkg + ";\n")
+	}
+
+	for _, desc := range descs {
+		buf.WriteString("\nmessage " + desc.Name + " {\n")
+		done := map[string]bool{}
+		for _, field := range desc.Fields {
+			if field.Oneof == "" {
+				line, err := protoFieldLine(pkg, proto3, field)
+				if err != nil {
+					return errors.New(desc.Name + "." + field.GoName + ": " + err.Error())
+				}
+				buf.WriteString("  " + line + "\n")
+				continue
+			}
+			if done[field.Oneof] {
+				continue
+			}
+			done[field.Oneof] = true
+
+			// Write all the fields of the oneof where the first one is
+			buf.WriteString("  oneof " + field.Oneof + " {\n")
+			for _, member := range desc.Fields {
+				if member.Oneof != field.Oneof {
+					continue
+				}
+				line, err := protoFieldLine(pkg, proto3, member)
+				if err != nil {
+					return errors.New(desc.Name + "." + member.GoName + ": " + err.Error())
+				}
+				buf.WriteString("    " + line + "\n")
+			}
+			buf.WriteString("  }\n")
+		}
+		buf.WriteString("}\n")
+	}
+
+	_, err := io.WriteString(w, buf.String())
+	return err
+}
+
+// protoFieldLine returns the declaration of field, e.g. "repeated int64 ids = 1;"
+func protoFieldLine(pkg string, proto3 bool, field FieldDescriptor) (string, error) {
+	info := field.Info
+
+	var typeName string
+	var err error
+	if field.GoType.Kind() == reflect.Map {
+		if field.MapKey == nil || field.MapValue == nil {
+			return "", errors.New("map field without protobuf_key and protobuf_val tags")
+		}
+		keyType, err := protoTypeName(pkg, field.MapKey, field.GoType.Key())
+		if err != nil {
+			return "", err
+		}
+		valType, err := protoTypeName(pkg, field.MapValue, field.GoType.Elem())
+		if err != nil {
+			return "", err
+		}
+		typeName = "map<" + keyType + ", " + valType + ">"
+	} else {
+		typ := field.GoType
+		if info.Repeated && typ.Kind() == reflect.Slice {
+			typ = typ.Elem()
+		}
+		if typeName, err = protoTypeName(pkg, info, typ); err != nil {
+			return "", err
+		}
+	}
+
+	label := ""
+	switch {
+	case field.GoType.Kind() == reflect.Map || field.Oneof != "":
+		// Maps and oneof fields have no label
+	case info.Repeated:
+		label = "repeated "
+	case !proto3:
+		label = field.Cardinality + " "
+	}
+
+	name := field.Name
+	if name == "" {
+		name = SnakeCase(field.GoName)
+	}
+
+	var options []string
+	if info.Repeated && info.Type != "bytes" && info.Type != "group" {
+		// Repeated scalars are packed by default in proto3 only
+		if proto3 && !info.Packed {
+			options = append(options, "packed = false")
+		} else if !proto3 && info.Packed {
+			options = append(options, "packed = true")
+		}
+	}
+	if info.Default != "" {
+		def := info.Default
+		if typeName == "string" || typeName == "bytes" {
+			def = strconv.Quote(def)
+		}
+		options = append(options, "default = "+def)
+	}
+	if info.JSONName != "" && info.JSONName != ProtobufJSONName(name) {
+		options = append(options, "json_name = "+strconv.Quote(info.JSONName))
+	}
+
+	line := label + typeName + " " + name + " = " + strconv.Itoa(info.TagNumber)
+	if len(options) > 0 {
+		line += " [" + strings.Join(options, ", ") + "]"
+	}
+	return line + ";", nil
+}
+
+// protoTypeName returns the .proto type of a value of Go type typ encoded as
+// described by info
+func protoTypeName(pkg string, info *ProtobufInfo, typ reflect.Type) (string, error) {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+
+	if info.Enum != "" {
+		if pkg != "" && strings.HasPrefix(info.Enum, pkg+".") {
+			return info.Enum[len(pkg)+1:], nil
+		}
+		return info.Enum, nil
+	}
+
+	kind := typ.Kind()
+	switch info.Type {
+	case "varint":
+		switch kind {
+		case reflect.Bool:
+			return "bool", nil
+		case reflect.Int32:
+			return "int32", nil
+		case reflect.Int64, reflect.Int:
+			return "int64", nil
+		case reflect.Uint32:
+			return "uint32", nil
+		case reflect.Uint64, reflect.Uint:
+			return "uint64", nil
+		}
+	case "zigzag32":
+		if kind == reflect.Int32 {
+			return "sint32", nil
+		}
+	case "zigzag64":
+		if kind == reflect.Int64 || kind == reflect.Int {
+			return "sint64", nil
+		}
+	case "fixed32":
+		switch kind {
+		case reflect.Uint32:
+			return "fixed32", nil
+		case reflect.Int32:
+			return "sfixed32", nil
+		case reflect.Float32:
+			return "float", nil
+		}
+	case "fixed64":
+		switch kind {
+		case reflect.Uint64:
+			return "fixed64", nil
+		case reflect.Int64:
+			return "sfixed64", nil
+		case reflect.Float64:
+			return "double", nil
+		}
+	case "bytes", "group":
+		switch {
+		case kind == reflect.String:
+			return "string", nil
+		case kind == reflect.Slice && typ.Elem().Kind() == reflect.Uint8:
+			return "bytes", nil
+		case kind == reflect.Struct && typ.Name() != "":
+			return typ.Name(), nil
+		}
+	}
+
+	return "", errors.New("Go type " + typ.String() + " can't be encoded as " + info.Type)
+}
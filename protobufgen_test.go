@@ -0,0 +1,95 @@
+package utils
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type testLegacy struct {
+	Id               *int64   `protobuf:"varint,1,req,name=id" json:"id,omitempty"`
+	Title            *string  `protobuf:"bytes,2,opt,name=title,def=untitled" json:"title,omitempty"`
+	Scores           []uint32 `protobuf:"varint,3,rep,packed,name=scores" json:"scores,omitempty"`
+	Ratio            *float64 `protobuf:"fixed64,4,opt,name=ratio" json:"ratio,omitempty"`
+	CreatedBy        *string  `protobuf:"bytes,5,opt,name=created_by,json=createdBy" json:"created_by,omitempty"`
+	Note             *string  `protobuf:"bytes,9,opt,name=note,json=remark" json:"note,omitempty"`
+	XXX_unrecognized []byte   `json:"-"`
+}
+
+func TestGenerateProto(t *testing.T) {
+	var buf strings.Builder
+	if err := GenerateProto(&buf, "test", reflect.TypeOf(&testProfile{})); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `syntax = "proto3";
+
+package test;
+
+message testProfile {
+  string name = 1;
+  Status status = 2;
+  testAccount account = 3;
+  repeated testAccount history = 4;
+  map<string, testAccount> linked = 5;
+  bytes avatar = 6;
+}
+
+message testAccount {
+  int64 id = 1;
+  map<string, int32> scores = 2;
+  repeated sint64 deltas = 3;
+  oneof contact {
+    string email = 4;
+    uint64 phone = 5;
+  }
+}
+`
+	if buf.String() != expected {
+		t.Errorf("got\n%s\nexpected\n%s", buf.String(), expected)
+	}
+}
+
+func TestGenerateProto2(t *testing.T) {
+	var buf strings.Builder
+	if err := GenerateProto(&buf, "", reflect.TypeOf(testLegacy{})); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `syntax = "proto2";
+
+message testLegacy {
+  required int64 id = 1;
+  optional string title = 2 [default = "untitled"];
+  repeated uint32 scores = 3 [packed = true];
+  optional double ratio = 4;
+  optional string created_by = 5;
+  optional string note = 9 [json_name = "remark"];
+}
+`
+	if buf.String() != expected {
+		t.Errorf("got\n%s\nexpected\n%s", buf.String(), expected)
+	}
+}
+
+func TestGenerateProtoErrors(t *testing.T) {
+	var buf strings.Builder
+	if err := GenerateProto(&buf, "", reflect.TypeOf(testLegacy{}), reflect.TypeOf(testAccount{})); err == nil {
+		t.Error("Expected an error when mixing proto2 and proto3")
+	}
+
+	anonymous := reflect.StructOf([]reflect.StructField{
+		{Name: "Id", Type: reflect.TypeOf(int64(0)), Tag: `protobuf:"varint,1,opt,name=id,proto3"`},
+	})
+	if err := GenerateProto(&buf, "", anonymous); err == nil {
+		t.Error("Expected an error for an anonymous struct")
+	}
+
+	type badType struct {
+		Id int8 `protobuf:"varint,1,opt,name=id,proto3"`
+	}
+	err := GenerateProto(&buf, "", reflect.TypeOf(badType{}))
+	if err == nil || err.Error() != "badType.Id: Go type int8 can't be encoded as varint" {
+		t.Errorf("got %v", err)
+	}
+}
@@ -0,0 +1,330 @@
+package utils
+
+import (
+	"encoding/base64"
+	"errors"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ProtobufJSONName returns the proto3 JSON name of a field declared as name
+// Underscores are removed and the letter following each one is upper cased,
+// exactly like protoc does. Nothing else changes, so unlike CamelCase,
+// "field_1_name" becomes "field1Name" and "foo2bar" stays "foo2bar".
+func ProtobufJSONName(name string) string {
+	var buf strings.Builder
+	upper := false
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		if c == '_' {
+			upper = true
+			continue
+		}
+		if upper && 'a' <= c && c <= 'z' {
+			c -= 'a' - 'A'
+		}
+		upper = false
+		buf.WriteByte(c)
+	}
+	return buf.String()
+}
+
+// JSONFieldName returns the proto3 JSON name of the field, which is the json=
+// option if given and ProtobufJSONName of the declared name otherwise
+func (pbInfo ProtobufInfo) JSONFieldName() string {
+	if pbInfo.JSONName != "" {
+		return pbInfo.JSONName
+	}
+	return ProtobufJSONName(pbInfo.Name)
+}
+
+// ProtobufToMap converts the generated protobuf struct msg into a map keyed by
+// the proto3 JSON names of the fields
+// Unset fields are left out, like in proto3 JSON. Nested messages become maps
+// too, repeated fields []interface{} and map fields map[string]interface{}
+// with their keys formatted as strings. Other values keep their Go type.
+func ProtobufToMap(msg interface{}) (map[string]interface{}, error) {
+	v := reflect.ValueOf(msg)
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil, nil
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil, errors.New("Not a struct")
+	}
+	return protobufToMap(v)
+}
+
+func protobufToMap(v reflect.Value) (map[string]interface{}, error) {
+	plan, err := protobufPlanOf(v.Type())
+	if err != nil {
+		return nil, err
+	}
+
+	m := map[string]interface{}{}
+	for _, f := range plan.fields {
+		fv := v.Field(f.index)
+
+		switch {
+		case f.wrapper != nil:
+			if fv.IsNil() || fv.Elem().Type() != f.wrapper || fv.Elem().IsNil() {
+				continue
+			}
+			m[f.jsonName], err = protobufToMapValue(fv.Elem().Elem().Field(0))
+
+		case f.key != nil:
+			if fv.Len() == 0 {
+				continue
+			}
+			entries := map[string]interface{}{}
+			for _, key := range fv.MapKeys() {
+				if entries[protobufMapKeyString(key)], err = protobufToMapValue(fv.MapIndex(key)); err != nil {
+					return nil, err
+				}
+			}
+			m[f.jsonName] = entries
+
+		case f.info.Repeated && fv.Kind() == reflect.Slice:
+			if fv.Len() == 0 {
+				continue
+			}
+			list := make([]interface{}, fv.Len())
+			for i := range list {
+				if list[i], err = protobufToMapValue(fv.Index(i)); err != nil {
+					return nil, err
+				}
+			}
+			m[f.jsonName] = list
+
+		default:
+			if isProtobufUnset(fv) {
+				continue
+			}
+			m[f.jsonName], err = protobufToMapValue(fv)
+		}
+
+		if err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+func protobufToMapValue(v reflect.Value) (interface{}, error) {
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			v = reflect.Zero(v.Type().Elem())
+		} else {
+			v = v.Elem()
+		}
+	}
+	switch v.Kind() {
+	case reflect.Struct:
+		return protobufToMap(v)
+	case reflect.Slice:
+		return append([]byte{}, v.Bytes()...), nil
+	}
+	return v.Interface(), nil
+}
+
+func protobufMapKeyString(key reflect.Value) string {
+	switch key.Kind() {
+	case reflect.String:
+		return key.String()
+	case reflect.Bool:
+		return strconv.FormatBool(key.Bool())
+	case reflect.Int, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(key.Int(), 10)
+	}
+	return strconv.FormatUint(key.Uint(), 10)
+}
+
+// MapToProtobuf sets the fields of the generated protobuf struct msg from m,
+// the reverse of ProtobufToMap
+// Fields can be given by their JSON name or their declared name. Numbers are
+// converted to the type of the field as long as they fit, and bytes fields
+// take base64 strings, so maps decoded by encoding/json work too. Nil values
+// are skipped and unknown fields are an error. Nil values in lists and maps are
+// left as zero values.
+func MapToProtobuf(m map[string]interface{}, msg interface{}) error {
+	v := reflect.ValueOf(msg)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return errors.New("Not a pointer to a struct")
+	}
+	return mapToProtobuf(m, v.Elem())
+}
+
+func mapToProtobuf(m map[string]interface{}, v reflect.Value) error {
+	plan, err := protobufPlanOf(v.Type())
+	if err != nil {
+		return err
+	}
+
+	// Sorted, so the same error is returned every time
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		val := m[name]
+		f := plan.byName[name]
+		if f == nil {
+			return errors.New("Unknown protobuf field " + strconv.Quote(name) + " in " + v.Type().Name())
+		}
+		if val == nil {
+			continue
+		}
+		fv := v.Field(f.index)
+
+		switch {
+		case f.wrapper != nil:
+			wrapper := reflect.New(f.wrapper.Elem())
+			err = setProtobufValue(wrapper.Elem().Field(0), val)
+			fv.Set(wrapper)
+
+		case f.key != nil:
+			entries, ok := val.(map[string]interface{})
+			if !ok {
+				err = errors.New("expected map[string]interface{}, got " + reflect.TypeOf(val).String())
+				break
+			}
+			if fv.IsNil() {
+				fv.Set(reflect.MakeMap(fv.Type()))
+			}
+			for keyStr, entry := range entries {
+				key := reflect.New(fv.Type().Key()).Elem()
+				elem := reflect.New(fv.Type().Elem()).Elem()
+				if err = setProtobufMapKey(key, keyStr); err == nil {
+					err = setProtobufValue(elem, entry)
+				}
+				if err != nil {
+					break
+				}
+				fv.SetMapIndex(key, elem)
+			}
+
+		case f.info.Repeated && fv.Kind() == reflect.Slice:
+			list, ok := val.([]interface{})
+			if !ok {
+				err = errors.New("expected []interface{}, got " + reflect.TypeOf(val).String())
+				break
+			}
+			slice := reflect.MakeSlice(fv.Type(), len(list), len(list))
+			for i := 0; i < len(list) && err == nil; i++ {
+				err = setProtobufValue(slice.Index(i), list[i])
+			}
+			fv.Set(slice)
+
+		default:
+			err = setProtobufValue(fv, val)
+		}
+
+		if err != nil {
+			return errors.New(v.Type().Name() + "." + f.name + ": " + err.Error())
+		}
+	}
+	return nil
+}
+
+func setProtobufValue(v reflect.Value, val interface{}) error {
+	if val == nil {
+		return nil
+	}
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		v = v.Elem()
+	}
+
+	if v.Kind() == reflect.Struct {
+		nested, ok := val.(map[string]interface{})
+		if !ok {
+			return errors.New("expected map[string]interface{}, got " + reflect.TypeOf(val).String())
+		}
+		return mapToProtobuf(nested, v)
+	}
+
+	rv := reflect.ValueOf(val)
+	switch {
+	case rv.Type().AssignableTo(v.Type()):
+		v.Set(rv)
+		return nil
+
+	case isNumberKind(rv.Kind()) && isNumberKind(v.Kind()):
+		converted := rv.Convert(v.Type())
+		fits := converted.Convert(rv.Type()).Interface() == val
+		if v.CanFloat() {
+			// Floats are rounded, they only have to be in range
+			fits = !math.IsInf(converted.Float(), 0) || rv.CanFloat() && math.IsInf(rv.Float(), 0)
+		}
+		if !fits {
+			return errors.New("value " + fmtNumber(rv) + " doesn't fit in " + v.Type().String())
+		}
+		v.Set(converted)
+		return nil
+
+	case rv.Kind() == reflect.String && v.Type() == reflect.TypeOf([]byte(nil)):
+		// encoding/json encodes bytes as base64
+		data, err := base64.StdEncoding.DecodeString(rv.String())
+		if err != nil {
+			return errors.New("invalid base64 for " + v.Type().String() + ": " + err.Error())
+		}
+		v.SetBytes(data)
+		return nil
+
+	case rv.Kind() == reflect.String && v.Kind() == reflect.String:
+		v.SetString(rv.String())
+		return nil
+
+	case rv.Kind() == reflect.Bool && v.Kind() == reflect.Bool:
+		v.SetBool(rv.Bool())
+		return nil
+	}
+	return errors.New("can't use " + rv.Type().String() + " as " + v.Type().String())
+}
+
+func setProtobufMapKey(v reflect.Value, str string) (err error) {
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(str)
+	case reflect.Bool:
+		var b bool
+		b, err = strconv.ParseBool(str)
+		v.SetBool(b)
+	case reflect.Int, reflect.Int32, reflect.Int64:
+		var n int64
+		n, err = strconv.ParseInt(str, 10, v.Type().Bits())
+		v.SetInt(n)
+	default:
+		var n uint64
+		n, err = strconv.ParseUint(str, 10, v.Type().Bits())
+		v.SetUint(n)
+	}
+	if err != nil {
+		return errors.New("invalid map key " + strconv.Quote(str) + " for " + v.Type().String())
+	}
+	return nil
+}
+
+func isNumberKind(kind reflect.Kind) bool {
+	return kind >= reflect.Int && kind <= reflect.Float64
+}
+
+// fmtNumber formats a number of any kind
+func fmtNumber(v reflect.Value) string {
+	switch {
+	case v.Kind() >= reflect.Int && v.Kind() <= reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10)
+	case v.Kind() >= reflect.Uint && v.Kind() <= reflect.Uintptr:
+		return strconv.FormatUint(v.Uint(), 10)
+	}
+	return strconv.FormatFloat(v.Float(), 'g', -1, 64)
+}
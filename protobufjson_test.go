@@ -0,0 +1,192 @@
+package utils
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestProtobufJSONName(t *testing.T) {
+	var tests = []sample{
+		{"foo_bar", "fooBar"},
+		{"foo", "foo"},
+		{"field_1_name", "field1Name"},
+		{"foo2bar", "foo2bar"},
+		{"foo_2bar", "foo2bar"},
+		{"address_v2", "addressV2"},
+		{"Foo_bar", "FooBar"},
+		{"fooBar", "fooBar"},
+		{"_foo", "Foo"},
+		{"foo__bar", "fooBar"},
+		{"foo_", "foo"},
+		{"", ""},
+	}
+
+	for _, test := range tests {
+		if out := ProtobufJSONName(test.str); out != test.out {
+			t.Errorf("got %q from %q, expected %q", out, test.str, test.out)
+		}
+	}
+}
+
+func TestJSONFieldName(t *testing.T) {
+	var tests = []sample{
+		{"bytes,5,opt,name=created_by,json=createdBy", "createdBy"},
+		{"bytes,9,opt,name=note,json=remark", "remark"},
+		{"varint,1,opt,name=line_2,proto3", "line2"},
+	}
+
+	for _, test := range tests {
+		pbInfo, _ := ParseProtobufInfo(test.str)
+		if out := pbInfo.JSONFieldName(); out != test.out {
+			t.Errorf("got %q from %q, expected %q", out, test.str, test.out)
+		}
+	}
+}
+
+func TestProtobufToMap(t *testing.T) {
+	note := "hi"
+	msg := &testProfile{
+		Name:    "Ada",
+		Status:  2,
+		Account: &testAccount{Id: 7, Contact: &testAccount_Email{Email: "ada@example.com"}},
+		History: []*testAccount{{Deltas: []int64{1}}},
+		Linked:  map[string]*testAccount{"x": {}},
+	}
+
+	m, err := ProtobufToMap(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]interface{}{
+		"name":    "Ada",
+		"status":  testStatus(2),
+		"account": map[string]interface{}{"id": int64(7), "email": "ada@example.com"},
+		"history": []interface{}{map[string]interface{}{"deltas": []interface{}{int64(1)}}},
+		"linked":  map[string]interface{}{"x": map[string]interface{}{}},
+	}
+	if !reflect.DeepEqual(m, expected) {
+		t.Errorf("got %+v, expected %+v", m, expected)
+	}
+
+	back := &testProfile{}
+	if err := MapToProtobuf(m, back); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(back, msg) {
+		t.Errorf("got %+v, expected %+v", back, msg)
+	}
+
+	m, err = ProtobufToMap(testLegacy{Id: new(int64), CreatedBy: &note, Note: &note})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected = map[string]interface{}{"id": int64(0), "createdBy": "hi", "remark": "hi"}
+	if !reflect.DeepEqual(m, expected) {
+		t.Errorf("got %+v, expected %+v", m, expected)
+	}
+}
+
+func TestMapToProtobufJSON(t *testing.T) {
+	// Maps decoded by encoding/json, using both JSON and declared names
+	var m map[string]interface{}
+	err := json.Unmarshal([]byte(`{
+		"name": "Ada",
+		"status": 3,
+		"account": {"id": 42, "scores": {"a": 1}, "phone": 4711},
+		"history": [{"deltas": [-1, 2]}, null]
+	}`), &m)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var msg testProfile
+	if err := MapToProtobuf(m, &msg); err != nil {
+		t.Fatal(err)
+	}
+	expected := testProfile{
+		Name:    "Ada",
+		Status:  3,
+		Account: &testAccount{Id: 42, Scores: map[string]int32{"a": 1}, Contact: &testAccount_Phone{Phone: 4711}},
+		History: []*testAccount{{Deltas: []int64{-1, 2}}, nil},
+	}
+	if !reflect.DeepEqual(msg, expected) {
+		t.Errorf("got %+v, expected %+v", msg, expected)
+	}
+
+	var legacy testLegacy
+	if err := MapToProtobuf(map[string]interface{}{"created_by": "a", "remark": "b"}, &legacy); err != nil {
+		t.Fatal(err)
+	}
+	if *legacy.CreatedBy != "a" || *legacy.Note != "b" {
+		t.Errorf("got %+v", legacy)
+	}
+}
+
+type testSample struct {
+	Value float32 `protobuf:"fixed32,1,opt,name=value,proto3" json:"value,omitempty"`
+	Raw   []byte  `protobuf:"bytes,2,opt,name=raw,proto3" json:"raw,omitempty"`
+}
+
+func TestProtobufJSONRoundTrip(t *testing.T) {
+	msg := testSample{Value: 0.1, Raw: []byte{0, 0xff, 'x'}}
+	m, err := ProtobufToMap(&msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := json.Marshal(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	var out testSample
+	if err := MapToProtobuf(decoded, &out); err != nil {
+		t.Fatalf("got %v from %s", err, data)
+	}
+	if !reflect.DeepEqual(out, msg) {
+		t.Errorf("got %+v from %s, expected %+v", out, data, msg)
+	}
+}
+
+func TestMapToProtobufErrors(t *testing.T) {
+	var tests = []struct {
+		in  map[string]interface{}
+		out string
+	}{
+		{map[string]interface{}{"nope": 1}, `Unknown protobuf field "nope" in testProfile`},
+		{map[string]interface{}{"status": 1.5}, "testProfile.Status: value 1.5 doesn't fit in utils.testStatus"},
+		{map[string]interface{}{"name": 1}, "testProfile.Name: can't use int as string"},
+		{map[string]interface{}{"history": "x"}, "testProfile.History: expected []interface{}, got string"},
+		{map[string]interface{}{"account": map[string]interface{}{"id": "x"}}, "testProfile.Account: testAccount.Id: can't use string as int64"},
+		{map[string]interface{}{"account": map[string]interface{}{"scores": map[string]interface{}{"a": 1 << 40}}}, "testProfile.Account: testAccount.Scores: value 1099511627776 doesn't fit in int32"},
+	}
+
+	for _, test := range tests {
+		err := MapToProtobuf(test.in, &testProfile{})
+		if err == nil || err.Error() != test.out {
+			t.Errorf("got %v from %v, expected %q", err, test.in, test.out)
+		}
+	}
+
+	var sampleTests = []struct {
+		in  map[string]interface{}
+		out string
+	}{
+		{map[string]interface{}{"value": 1e40}, "testSample.Value: value 1e+40 doesn't fit in float32"},
+		{map[string]interface{}{"raw": "!"}, "testSample.Raw: invalid base64 for []uint8: illegal base64 data at input byte 0"},
+	}
+	for _, test := range sampleTests {
+		err := MapToProtobuf(test.in, &testSample{})
+		if err == nil || err.Error() != test.out {
+			t.Errorf("got %v from %v, expected %q", err, test.in, test.out)
+		}
+	}
+
+	if err := MapToProtobuf(nil, testProfile{}); err == nil {
+		t.Error("Expected an error for a non-pointer")
+	}
+}
@@ -0,0 +1,293 @@
+package utils
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+	"strconv"
+)
+
+// MessageDescriptor describes a protobuf message derived from a generated Go
+// struct, see DescribeMessage
+type MessageDescriptor struct {
+	Name   string       // Go type name
+	GoType reflect.Type // The struct type
+	Proto3 bool         // Whether the fields are tagged proto3
+	Fields []FieldDescriptor
+	Oneofs []string // Names of the oneofs, in declaration order
+}
+
+// FieldDescriptor describes a single field of a protobuf message
+type FieldDescriptor struct {
+	Name        string        // The declared protobuf name
+	GoName      string        // The Go field name, in the oneof wrapper for oneof fields
+	Number      int           // The tag number
+	WireType    string        // The wire encoding, e.g. "varint" or "bytes"
+	Cardinality string        // "optional", "required" or "repeated"
+	Enum        string        // The enum type name for enum fields
+	Oneof       string        // The name of the containing oneof, if any
+	GoType      reflect.Type  // The Go type of the field
+	Message     reflect.Type  // The struct type of message fields, nil for scalars
+	MapKey      *ProtobufInfo // The protobuf_key info of map fields
+	MapValue    *ProtobufInfo // The protobuf_val info of map fields
+	Info        *ProtobufInfo // The full info of the protobuf tag
+}
+
+// Cardinality returns "optional", "required" or "repeated"
+func (pbInfo ProtobufInfo) Cardinality() string {
+	switch {
+	case pbInfo.Repeated:
+		return "repeated"
+	case pbInfo.Required:
+		return "required"
+	}
+	return "optional"
+}
+
+// DescribeMessage derives the message schema of the generated protobuf struct
+// typ from its protobuf tags
+// typ must be a struct or a pointer to one. Fields without a protobuf tag,
+// like the XXX_ fields, are skipped. Oneof fields are listed with the fields of
+// their wrapper types. The fields are sorted by tag number.
+func DescribeMessage(typ reflect.Type) (*MessageDescriptor, error) {
+	for typ != nil && typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ == nil || typ.Kind() != reflect.Struct {
+		return nil, errors.New("Not a struct")
+	}
+
+	desc := &MessageDescriptor{Name: typ.Name(), GoType: typ}
+
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		tag := TagString(field.Tag)
+
+		pbTag, ok := tag.Lookup("protobuf")
+		if !ok {
+			continue
+		}
+		pbInfo, err := ParseProtobufInfo(pbTag)
+		if err != nil {
+			return nil, errors.New(typ.Name() + "." + field.Name + ": " + err.Error())
+		}
+
+		fieldDesc := newFieldDescriptor(field, pbInfo)
+		if field.Type.Kind() == reflect.Map {
+			if fieldDesc.MapKey, err = ParseProtobufInfo(tag.Get("protobuf_key")); err != nil {
+				return nil, errors.New(typ.Name() + "." + field.Name + ": " + err.Error())
+			}
+			if fieldDesc.MapValue, err = ParseProtobufInfo(tag.Get("protobuf_val")); err != nil {
+				return nil, errors.New(typ.Name() + "." + field.Name + ": " + err.Error())
+			}
+			fieldDesc.Message = messageType(field.Type.Elem())
+		}
+		desc.Fields = append(desc.Fields, fieldDesc)
+	}
+
+	for _, oneof := range ProtobufOneofs(typ) {
+		if len(oneof.Choices) == 0 {
+			return nil, errNoOneofChoices(typ, oneof)
+		}
+		desc.Oneofs = append(desc.Oneofs, oneof.Name)
+		for _, choice := range oneof.Choices {
+			if choice.Info == nil {
+				return nil, errors.New(typ.Name() + "." + oneof.Field + ": invalid protobuf tag in " + choice.Wrapper.String())
+			}
+			wrapperField, _ := choice.Wrapper.Elem().FieldByName(choice.Field)
+			fieldDesc := newFieldDescriptor(wrapperField, choice.Info)
+			fieldDesc.Oneof = oneof.Name
+			desc.Fields = append(desc.Fields, fieldDesc)
+		}
+	}
+
+	sort.SliceStable(desc.Fields, func(i, j int) bool {
+		return desc.Fields[i].Number < desc.Fields[j].Number
+	})
+	for _, field := range desc.Fields {
+		if field.Info.Proto3 {
+			desc.Proto3 = true
+			break
+		}
+	}
+
+	return desc, nil
+}
+
+// DescribeMessageOf derives the message schema of the type of msg
+func DescribeMessageOf(msg interface{}) (*MessageDescriptor, error) {
+	return DescribeMessage(reflect.TypeOf(msg))
+}
+
+// errNoOneofChoices is returned for oneofs whose choices can't be found, as
+// they're listed by the XXX_OneofWrappers method, which protoc-gen-go from
+// google.golang.org/protobuf doesn't generate, or the field isn't an interface
+func errNoOneofChoices(typ reflect.Type, oneof ProtobufOneofInfo) error {
+	field, _ := typ.FieldByName(oneof.Field)
+	if field.Type.Kind() != reflect.Interface {
+		return errors.New(typ.Name() + "." + oneof.Field + ": oneof " + oneof.Name + " must be an interface, got " + field.Type.String())
+	}
+	return errors.New(typ.Name() + "." + oneof.Field + ": can't find the choices of oneof " + oneof.Name +
+		", there is no XXX_OneofWrappers method")
+}
+
+func newFieldDescriptor(field reflect.StructField, pbInfo *ProtobufInfo) FieldDescriptor {
+	fieldDesc := FieldDescriptor{
+		Name:        pbInfo.Name,
+		GoName:      field.Name,
+		Number:      pbInfo.TagNumber,
+		WireType:    pbInfo.Type,
+		Cardinality: pbInfo.Cardinality(),
+		Enum:        pbInfo.Enum,
+		GoType:      field.Type,
+		Info:        pbInfo,
+	}
+	if pbInfo.Type == "bytes" || pbInfo.Type == "group" {
+		elem := field.Type
+		if elem.Kind() == reflect.Slice && elem.Elem().Kind() != reflect.Uint8 {
+			elem = elem.Elem()
+		}
+		fieldDesc.Message = messageType(elem)
+	}
+	return fieldDesc
+}
+
+// messageType returns the struct type of a message field, or nil
+func messageType(typ reflect.Type) reflect.Type {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ.Kind() == reflect.Struct {
+		return typ
+	}
+	return nil
+}
+
+// ProtobufSchemaChange is a wire incompatible change between two versions of a
+// message, see CompareProtobufSchemas
+type ProtobufSchemaChange struct {
+	Message string // The name of the message in the new version
+	Field   string // The protobuf name of the field
+	Number  int    // The tag number of the field
+	Problem string
+}
+
+func (c ProtobufSchemaChange) String() string {
+	return c.Message + "." + c.Field + " = " + strconv.Itoa(c.Number) + ": " + c.Problem
+}
+
+// CompareProtobufSchemas reports the changes from old to new that break
+// messages encoded by one version and decoded by the other
+// Tag numbers reused with a different encoding, message type or enum type,
+// changed cardinality and added or removed required fields are reported.
+// Fields of nested messages are compared too.
+func CompareProtobufSchemas(old, new reflect.Type) (changes []ProtobufSchemaChange, err error) {
+	return compareProtobufSchemas(old, new, map[[2]reflect.Type]bool{})
+}
+
+func compareProtobufSchemas(old, new reflect.Type, seen map[[2]reflect.Type]bool) (changes []ProtobufSchemaChange, err error) {
+	oldDesc, err := DescribeMessage(old)
+	if err != nil {
+		return nil, err
+	}
+	newDesc, err := DescribeMessage(new)
+	if err != nil {
+		return nil, err
+	}
+	// Recursive messages are only compared once
+	pair := [2]reflect.Type{oldDesc.GoType, newDesc.GoType}
+	if seen[pair] {
+		return nil, nil
+	}
+	seen[pair] = true
+
+	oldFields := map[int]FieldDescriptor{}
+	for _, field := range oldDesc.Fields {
+		oldFields[field.Number] = field
+	}
+	newFields := map[int]FieldDescriptor{}
+	for _, field := range newDesc.Fields {
+		newFields[field.Number] = field
+	}
+
+	report := func(field FieldDescriptor, problem string) {
+		changes = append(changes, ProtobufSchemaChange{Message: newDesc.Name, Field: field.Name, Number: field.Number, Problem: problem})
+	}
+
+	for _, oldField := range oldDesc.Fields {
+		newField, ok := newFields[oldField.Number]
+		if !ok {
+			if oldField.Cardinality == "required" {
+				report(oldField, "required field removed")
+			}
+			continue
+		}
+
+		if oldField.Cardinality != newField.Cardinality {
+			report(newField, "cardinality changed from "+oldField.Cardinality+" to "+newField.Cardinality)
+		}
+
+		oldMap := oldField.GoType.Kind() == reflect.Map
+		newMap := newField.GoType.Kind() == reflect.Map
+		switch {
+		case oldMap != newMap:
+			report(newField, "changed between map and non-map field")
+		case oldMap:
+			if problem := compareProtobufValues("key", oldField.MapKey, newField.MapKey, nil, nil); problem != "" {
+				report(newField, problem)
+			}
+			if problem := compareProtobufValues("value", oldField.MapValue, newField.MapValue, oldField.Message, newField.Message); problem != "" {
+				report(newField, problem)
+			}
+		default:
+			if problem := compareProtobufValues("", oldField.Info, newField.Info, oldField.Message, newField.Message); problem != "" {
+				report(newField, problem)
+			}
+		}
+
+		if oldField.Message != nil && newField.Message != nil {
+			nested, err := compareProtobufSchemas(oldField.Message, newField.Message, seen)
+			if err != nil {
+				return nil, err
+			}
+			changes = append(changes, nested...)
+		}
+	}
+
+	for _, newField := range newDesc.Fields {
+		if _, ok := oldFields[newField.Number]; !ok && newField.Cardinality == "required" {
+			report(newField, "required field added")
+		}
+	}
+
+	return changes, nil
+}
+
+// compareProtobufValues describes the incompatible change from old to new
+// of a field, map key or map value, or returns ""
+func compareProtobufValues(what string, old, new *ProtobufInfo, oldMessage, newMessage reflect.Type) string {
+	if what != "" {
+		what = " of the map " + what
+	}
+	if old == nil || new == nil {
+		return "missing protobuf info" + what
+	}
+	if old.Type != new.Type {
+		return "encoding" + what + " changed from " + old.Type + " to " + new.Type
+	}
+	if (oldMessage == nil) != (newMessage == nil) {
+		return "type" + what + " changed from " + protobufValueKind(old, oldMessage) + " to " + protobufValueKind(new, newMessage)
+	}
+	if old.Enum != "" && new.Enum != "" && old.Enum != new.Enum {
+		return "enum type" + what + " renamed from " + old.Enum + " to " + new.Enum
+	}
+	return ""
+}
+
+// protobufValueKind describes what a field holds, for ProtobufSchemaChange
+func protobufValueKind(info *ProtobufInfo, message reflect.Type) string {
+	if message != nil {
+		return "message " + message.Name()
+	}
+	return info.Type
+}
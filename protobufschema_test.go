@@ -0,0 +1,192 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+)
+
+type testStatus int32
+
+//	message Profile {
+//	  string name = 1;
+//	  Status status = 2;
+//	  Account account = 3;
+//	  repeated Account history = 4;
+//	  map<string, Account> linked = 5;
+//	  bytes avatar = 6;
+//	}
+type testProfile struct {
+	Name                 string                  `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Status               testStatus              `protobuf:"varint,2,opt,name=status,proto3,enum=test.Status" json:"status,omitempty"`
+	Account              *testAccount            `protobuf:"bytes,3,opt,name=account,proto3" json:"account,omitempty"`
+	History              []*testAccount          `protobuf:"bytes,4,rep,name=history,proto3" json:"history,omitempty"`
+	Linked               map[string]*testAccount `protobuf:"bytes,5,rep,name=linked,proto3" json:"linked,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	Avatar               []byte                  `protobuf:"bytes,6,opt,name=avatar,proto3" json:"avatar,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}                `json:"-"`
+}
+
+func TestDescribeMessage(t *testing.T) {
+	desc, err := DescribeMessage(reflect.TypeOf(&testAccount{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if desc.Name != "testAccount" || !desc.Proto3 || !reflect.DeepEqual(desc.Oneofs, []string{"contact"}) {
+		t.Errorf("got %+v", desc)
+	}
+
+	var expected = []struct {
+		name, goName, wireType, cardinality, oneof string
+		number                                     int
+		goType                                     reflect.Type
+	}{
+		{"id", "Id", "varint", "optional", "", 1, reflect.TypeOf(int64(0))},
+		{"scores", "Scores", "bytes", "repeated", "", 2, reflect.TypeOf(map[string]int32{})},
+		{"deltas", "Deltas", "zigzag64", "repeated", "", 3, reflect.TypeOf([]int64{})},
+		{"email", "Email", "bytes", "optional", "contact", 4, reflect.TypeOf("")},
+		{"phone", "Phone", "varint", "optional", "contact", 5, reflect.TypeOf(uint64(0))},
+	}
+	if len(desc.Fields) != len(expected) {
+		t.Fatalf("got %d fields, expected %d", len(desc.Fields), len(expected))
+	}
+	for i, field := range desc.Fields {
+		e := expected[i]
+		if field.Name != e.name || field.GoName != e.goName || field.WireType != e.wireType ||
+			field.Cardinality != e.cardinality || field.Oneof != e.oneof || field.Number != e.number || field.GoType != e.goType {
+			t.Errorf("got %+v, expected %+v", field, e)
+		}
+	}
+
+	scores := desc.Fields[1]
+	if scores.MapKey == nil || scores.MapKey.Type != "bytes" || scores.MapValue == nil || scores.MapValue.Type != "varint" {
+		t.Errorf("got map info %+v %+v", scores.MapKey, scores.MapValue)
+	}
+	if scores.Message != nil {
+		t.Errorf("got message %v for a map of scalars", scores.Message)
+	}
+}
+
+func TestDescribeMessageNested(t *testing.T) {
+	desc, err := DescribeMessageOf(testProfile{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(desc.Fields) != 6 || desc.Oneofs != nil {
+		t.Fatalf("got %+v", desc)
+	}
+
+	account := reflect.TypeOf(testAccount{})
+	var expected = []struct {
+		enum    string
+		message reflect.Type
+	}{
+		{"", nil},
+		{"test.Status", nil},
+		{"", account},
+		{"", account},
+		{"", account},
+		{"", nil},
+	}
+	for i, field := range desc.Fields {
+		if field.Enum != expected[i].enum || field.Message != expected[i].message {
+			t.Errorf("got %s %q %v, expected %+v", field.Name, field.Enum, field.Message, expected[i])
+		}
+	}
+}
+
+func TestDescribeMessageErrors(t *testing.T) {
+	if _, err := DescribeMessage(reflect.TypeOf(0)); err == nil {
+		t.Error("Expected an error for a non-struct")
+	}
+
+	typ := reflect.StructOf([]reflect.StructField{
+		{Name: "Id", Type: reflect.TypeOf(0), Tag: `protobuf:"varint,0,opt,name=id"`},
+	})
+	_, err := DescribeMessage(typ)
+	if err == nil || err.Error() != `.Id: Invalid protobuf tag "varint,0,opt,name=id": tag number 0 is out of range 1 to 536870911` {
+		t.Errorf("got %v", err)
+	}
+}
+
+func TestProtobufInfoCardinality(t *testing.T) {
+	var tests = []sample{
+		{"varint,1,opt,name=id", "optional"},
+		{"varint,1,req,name=id", "required"},
+		{"varint,1,rep,name=id", "repeated"},
+		{"varint,1,opt,name=id,proto3", "optional"},
+	}
+	for _, test := range tests {
+		pbInfo, _ := ParseProtobufInfo(test.str)
+		if out := pbInfo.Cardinality(); out != test.out {
+			t.Errorf("got %q from %q, expected %q", out, test.str, test.out)
+		}
+	}
+}
+
+type testAccountV2 struct {
+	Id     int64            `protobuf:"zigzag64,1,opt,name=id,proto3" json:"id,omitempty"`
+	Scores map[string]int64 `protobuf:"bytes,2,rep,name=scores,proto3" json:"scores,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"varint,2,opt,name=value,proto3"`
+	Deltas []int64          `protobuf:"zigzag64,3,rep,packed,name=deltas,proto3" json:"deltas,omitempty"`
+}
+
+type testProfileV2 struct {
+	Name    string                    `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Status  testStatus                `protobuf:"varint,2,opt,name=status,proto3,enum=test.State" json:"status,omitempty"`
+	Account *testAccountV2            `protobuf:"bytes,3,opt,name=account,proto3" json:"account,omitempty"`
+	History []*testAccountV2          `protobuf:"bytes,4,rep,name=history,proto3" json:"history,omitempty"`
+	Linked  map[string]string         `protobuf:"bytes,5,rep,name=linked,proto3" json:"linked,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	Avatars [][]byte                  `protobuf:"bytes,6,rep,name=avatars,proto3" json:"avatars,omitempty"`
+	Tags    map[string]*testAccountV2 `protobuf:"bytes,7,rep,name=tags,proto3" json:"tags,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+}
+
+type testLegacyV2 struct {
+	Title  *string  `protobuf:"bytes,2,req,name=title" json:"title,omitempty"`
+	Scores []uint32 `protobuf:"varint,3,rep,packed,name=scores" json:"scores,omitempty"`
+	Ratio  *float64 `protobuf:"fixed64,4,opt,name=ratio" json:"ratio,omitempty"`
+	Code   *string  `protobuf:"bytes,6,req,name=code" json:"code,omitempty"`
+}
+
+func TestCompareProtobufSchemas(t *testing.T) {
+	changes, err := CompareProtobufSchemas(reflect.TypeOf(testProfile{}), reflect.TypeOf(&testProfileV2{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{
+		"testProfileV2.status = 2: enum type renamed from test.Status to test.State",
+		"testAccountV2.id = 1: encoding changed from varint to zigzag64",
+		"testProfileV2.linked = 5: type of the map value changed from message testAccount to bytes",
+		"testProfileV2.avatars = 6: cardinality changed from optional to repeated",
+	}
+	compareSchemaChanges(t, changes, expected)
+
+	changes, err = CompareProtobufSchemas(reflect.TypeOf(testLegacy{}), reflect.TypeOf(testLegacyV2{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected = []string{
+		"testLegacyV2.id = 1: required field removed",
+		"testLegacyV2.title = 2: cardinality changed from optional to required",
+		"testLegacyV2.code = 6: required field added",
+	}
+	compareSchemaChanges(t, changes, expected)
+
+	changes, err = CompareProtobufSchemas(reflect.TypeOf(testAccount{}), reflect.TypeOf(testAccount{}))
+	if err != nil || len(changes) != 0 {
+		t.Errorf("got %v, %v comparing a message to itself", changes, err)
+	}
+
+	if _, err := CompareProtobufSchemas(reflect.TypeOf(testAccount{}), reflect.TypeOf("")); err == nil {
+		t.Error("Expected an error for a non-struct")
+	}
+}
+
+func compareSchemaChanges(t *testing.T, changes []ProtobufSchemaChange, expected []string) {
+	t.Helper()
+	if len(changes) != len(expected) {
+		t.Fatalf("got %d changes %v, expected %d", len(changes), changes, len(expected))
+	}
+	for i, change := range changes {
+		if change.String() != expected[i] {
+			t.Errorf("got %q, expected %q", change.String(), expected[i])
+		}
+	}
+}
@@ -1,6 +1,8 @@
 package utils
 
 import (
+	"errors"
+	"reflect"
 	"strconv"
 	"strings"
 )
@@ -16,24 +18,98 @@ type ProtobufInfo struct {
 	Name      string // name= the original declared name
 	Enum      string // enum= the name of the enum type if it is an enum-typed field.
 	Proto3    bool   // proto3 if this field is in a proto3 message
-
-	// Seems like these 2 are never used - at least in proto3.
-	// Packed  bool   // packed whether the encoding is "packed" (optional; repeated primitives only)
-	// Default string // def= string representation of the default value, if any.
+	Packed    bool   // packed whether the encoding is "packed" (optional; repeated primitives only)
+	JSONName  string // json= the JSON name, if it differs from the declared name
+	Oneof     bool   // oneof if this field is a member of a oneof
+	Default   string // def= string representation of the default value, if any.
 }
 
+// ProtobufInfo returns the info of the protobuf tag
+// nil is returned if the tag is missing or invalid, use ParseProtobufInfo to
+// find out what is wrong with it.
 func (tag *TagString) ProtobufInfo() (pbInfo *ProtobufInfo) {
-	pbTag := tag.Get("protobuf")
+	return parseProtobufInfo(tag.Get("protobuf"))
+}
+
+// ProtobufKeyInfo returns the info of the protobuf_key tag, which describes the
+// keys of map fields
+func (tag *TagString) ProtobufKeyInfo() *ProtobufInfo {
+	return parseProtobufInfo(tag.Get("protobuf_key"))
+}
+
+// ProtobufValInfo returns the info of the protobuf_val tag, which describes the
+// values of map fields
+func (tag *TagString) ProtobufValInfo() *ProtobufInfo {
+	return parseProtobufInfo(tag.Get("protobuf_val"))
+}
+
+// ProtobufOneof returns the name of the oneof in the protobuf_oneof tag
+// The tag is set on the interface field holding the oneof wrapper types.
+func (tag *TagString) ProtobufOneof() string {
+	return tag.Get("protobuf_oneof")
+}
+
+// Valid protobuf tag numbers
+const (
+	ProtobufMinTagNumber = 1
+	ProtobufMaxTagNumber = 1<<29 - 1
+
+	// Reserved for the protobuf implementation
+	ProtobufFirstReservedTagNumber = 19000
+	ProtobufLastReservedTagNumber  = 19999
+)
+
+// protobufWireTypes are the wire encodings used in protobuf tags
+var protobufWireTypes = map[string]bool{
+	"varint":   true,
+	"zigzag32": true,
+	"zigzag64": true,
+	"fixed32":  true,
+	"fixed64":  true,
+	"bytes":    true,
+	"group":    true,
+}
 
+func parseProtobufInfo(pbTag string) *ProtobufInfo {
 	if pbTag == "" {
-		return
+		return nil
 	}
+	pbInfo, _ := ParseProtobufInfo(pbTag)
+	return pbInfo
+}
 
+// ParseProtobufInfo parses a protobuf tag value like "bytes,1,opt,name=foo"
+// An error describing the problem is returned if the wire type, tag number or
+// cardinality is missing or invalid, or if packed is used on a field that
+// can't be packed. Unknown options are ignored, as golang/protobuf does.
+func ParseProtobufInfo(pbTag string) (pbInfo *ProtobufInfo, err error) {
 	keyVals := strings.Split(pbTag, ",")
+	if len(keyVals) < 3 {
+		missing := "tag number"
+		if len(keyVals) == 2 {
+			missing = "cardinality"
+		}
+		return nil, protobufTagError(pbTag, "missing "+missing)
+	}
+
+	if !protobufWireTypes[keyVals[0]] {
+		return nil, protobufTagError(pbTag, "unknown wire type "+strconv.Quote(keyVals[0]))
+	}
 	pbInfo = &ProtobufInfo{
 		Type: keyVals[0],
 	}
-	pbInfo.TagNumber, _ = strconv.Atoi(keyVals[1])
+
+	pbInfo.TagNumber, err = strconv.Atoi(keyVals[1])
+	if err != nil {
+		return nil, protobufTagError(pbTag, "tag number "+strconv.Quote(keyVals[1])+" is not a number")
+	}
+	if pbInfo.TagNumber < ProtobufMinTagNumber || pbInfo.TagNumber > ProtobufMaxTagNumber {
+		return nil, protobufTagError(pbTag, "tag number "+keyVals[1]+" is out of range "+
+			strconv.Itoa(ProtobufMinTagNumber)+" to "+strconv.Itoa(ProtobufMaxTagNumber))
+	}
+	if pbInfo.TagNumber >= ProtobufFirstReservedTagNumber && pbInfo.TagNumber <= ProtobufLastReservedTagNumber {
+		return nil, protobufTagError(pbTag, "tag number "+keyVals[1]+" is reserved for the protobuf implementation")
+	}
 
 	switch keyVals[2] {
 	case "opt":
@@ -43,17 +119,42 @@ func (tag *TagString) ProtobufInfo() (pbInfo *ProtobufInfo) {
 	case "rep":
 		pbInfo.Optional = true
 		pbInfo.Repeated = true
+	default:
+		return nil, protobufTagError(pbTag, "unknown cardinality "+strconv.Quote(keyVals[2])+", expected opt, req or rep")
 	}
 
 	for i := 3; i < len(keyVals); i++ {
 		if keyVals[i] == "proto3" {
 			pbInfo.Proto3 = true
 
+		} else if keyVals[i] == "packed" {
+			pbInfo.Packed = true
+
+		} else if keyVals[i] == "oneof" {
+			pbInfo.Oneof = true
+
 		} else if strings.HasPrefix(keyVals[i], "name=") {
 			pbInfo.Name = strings.Replace(keyVals[i], "name=", "", 1)
 
+		} else if strings.HasPrefix(keyVals[i], "json=") {
+			pbInfo.JSONName = strings.Replace(keyVals[i], "json=", "", 1)
+
 		} else if strings.HasPrefix(keyVals[i], "enum=") {
 			pbInfo.Enum = strings.Replace(keyVals[i], "enum=", "", 1)
+
+		} else if strings.HasPrefix(keyVals[i], "def=") {
+			// Commas aren't escaped, so def= is always last
+			pbInfo.Default = strings.Join(keyVals[i:], ",")[len("def="):]
+			break
+		}
+	}
+
+	if pbInfo.Packed {
+		if !pbInfo.Repeated {
+			return nil, protobufTagError(pbTag, "packed is only valid for repeated fields")
+		}
+		if pbInfo.Type == "bytes" || pbInfo.Type == "group" {
+			return nil, protobufTagError(pbTag, "packed is not valid for wire type "+pbInfo.Type)
 		}
 	}
 
@@ -64,3 +165,158 @@ func (tag *TagString) ProtobufInfo() (pbInfo *ProtobufInfo) {
 
 	return
 }
+
+func protobufTagError(pbTag, problem string) error {
+	return errors.New("Invalid protobuf tag " + strconv.Quote(pbTag) + ": " + problem)
+}
+
+// String returns the protobuf tag value in the canonical form used by
+// golang/protobuf, e.g. "bytes,1,opt,name=foo,proto3"
+func (pbInfo ProtobufInfo) String() string {
+	var buf strings.Builder
+	buf.WriteString(pbInfo.Type)
+	buf.WriteByte(',')
+	buf.WriteString(strconv.Itoa(pbInfo.TagNumber))
+
+	switch {
+	case pbInfo.Repeated:
+		buf.WriteString(",rep")
+	case pbInfo.Required:
+		buf.WriteString(",req")
+	case pbInfo.Optional:
+		buf.WriteString(",opt")
+	}
+
+	if pbInfo.Packed {
+		buf.WriteString(",packed")
+	}
+	if pbInfo.Name != "" {
+		buf.WriteString(",name=")
+		buf.WriteString(pbInfo.Name)
+	}
+	if pbInfo.JSONName != "" {
+		buf.WriteString(",json=")
+		buf.WriteString(pbInfo.JSONName)
+	}
+	if pbInfo.Proto3 {
+		buf.WriteString(",proto3")
+	}
+	if pbInfo.Enum != "" {
+		buf.WriteString(",enum=")
+		buf.WriteString(pbInfo.Enum)
+	}
+	if pbInfo.Oneof {
+		buf.WriteString(",oneof")
+	}
+	// Must be last, as commas in the default value aren't escaped
+	if pbInfo.Default != "" {
+		buf.WriteString(",def=")
+		buf.WriteString(pbInfo.Default)
+	}
+
+	return buf.String()
+}
+
+// TagValue returns the protobuf tag value, see String
+func (pbInfo ProtobufInfo) TagValue() TagValue {
+	return TagValue(pbInfo.String())
+}
+
+// SetProtobufInfo adds the protobuf tag described by pbInfo, replacing any
+// existing protobuf tag
+func (tag *TagString) SetProtobufInfo(pbInfo *ProtobufInfo) {
+	tag.Add("protobuf", pbInfo.String())
+}
+
+// SetProtobufMapInfo adds the protobuf_key and protobuf_val tags of a map field
+func (tag *TagString) SetProtobufMapInfo(keyInfo, valInfo *ProtobufInfo) {
+	tag.Add("protobuf_key", keyInfo.String())
+	tag.Add("protobuf_val", valInfo.String())
+}
+
+// ProtobufOneofInfo describes a oneof of a generated message
+type ProtobufOneofInfo struct {
+	Name    string // The name of the oneof, from the protobuf_oneof tag
+	Field   string // The Go field holding the oneof
+	Choices []ProtobufOneofChoice
+}
+
+// ProtobufOneofChoice is one of the fields of a oneof
+type ProtobufOneofChoice struct {
+	Wrapper reflect.Type  // The wrapper type, e.g. *Msg_Name
+	Field   string        // The Go field of the wrapper holding the value
+	Info    *ProtobufInfo // The info of the field
+}
+
+// ProtobufOneofs returns the oneofs of the generated message type typ
+// The wrapper types are found with the XXX_OneofWrappers method generated by
+// golang/protobuf, or XXX_OneofFuncs for older versions. Only pointers to
+// structs are used as wrappers, and oneofs whose field isn't an interface
+// have no choices.
+func ProtobufOneofs(typ reflect.Type) (oneofs []ProtobufOneofInfo) {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ.Kind() != reflect.Struct {
+		return
+	}
+
+	wrappers := protobufOneofWrappers(typ)
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		tag := TagString(field.Tag)
+		name := tag.ProtobufOneof()
+		if name == "" {
+			continue
+		}
+
+		oneof := ProtobufOneofInfo{Name: name, Field: field.Name}
+		if field.Type.Kind() != reflect.Interface {
+			oneofs = append(oneofs, oneof)
+			continue
+		}
+		for _, wrapper := range wrappers {
+			if wrapper.Kind() != reflect.Ptr || !wrapper.Implements(field.Type) {
+				continue
+			}
+			wrapperStruct := wrapper.Elem()
+			if wrapperStruct.Kind() != reflect.Struct || wrapperStruct.NumField() == 0 {
+				continue
+			}
+			wrapperField := wrapperStruct.Field(0)
+			wrapperTag := TagString(wrapperField.Tag)
+			oneof.Choices = append(oneof.Choices, ProtobufOneofChoice{
+				Wrapper: wrapper,
+				Field:   wrapperField.Name,
+				Info:    wrapperTag.ProtobufInfo(),
+			})
+		}
+		oneofs = append(oneofs, oneof)
+	}
+	return
+}
+
+// protobufOneofWrappers returns the types returned by XXX_OneofWrappers or
+// the last result of XXX_OneofFuncs
+func protobufOneofWrappers(typ reflect.Type) (wrappers []reflect.Type) {
+	msg := reflect.New(typ)
+	method := msg.MethodByName("XXX_OneofWrappers")
+	if !method.IsValid() {
+		method = msg.MethodByName("XXX_OneofFuncs")
+	}
+	if !method.IsValid() || method.Type().NumIn() != 0 || method.Type().NumOut() == 0 {
+		return
+	}
+
+	out := method.Call(nil)
+	list, ok := out[len(out)-1].Interface().([]interface{})
+	if !ok {
+		return
+	}
+	for _, wrapper := range list {
+		if wrapper != nil {
+			wrappers = append(wrappers, reflect.TypeOf(wrapper))
+		}
+	}
+	return
+}
@@ -1,6 +1,10 @@
 package utils
 
-import "testing"
+import (
+	"reflect"
+	"strconv"
+	"testing"
+)
 
 func TestProtobufInfo(t *testing.T) {
 	var tests = []struct {
@@ -9,27 +13,39 @@ func TestProtobufInfo(t *testing.T) {
 	}{
 		{
 			`protobuf:"bytes,4,opt,name=comment,proto3" json:"comment,omitempty" col:"comment" default:"true" nullable:"false"`,
-			ProtobufInfo{"bytes", 4, true, false, false, "comment", "", true},
+			ProtobufInfo{Type: "bytes", TagNumber: 4, Optional: true, Name: "comment", Proto3: true},
 		},
 		{
 			`protobuf:"varint,5,opt,name=type,proto3,enum=models.CommentType" json:"type,omitempty" col:"type" default:"true" nullable:"false"`,
-			ProtobufInfo{"varint", 5, true, false, false, "type", "models.CommentType", true},
+			ProtobufInfo{Type: "varint", TagNumber: 5, Optional: true, Name: "type", Enum: "models.CommentType", Proto3: true},
 		},
 		{
 			`protobuf:"bytes,6,opt,name=created,stdtime" json:"created,omitempty" col:"created" default:"true" nullable:"false"`,
-			ProtobufInfo{"bytes", 6, true, false, false, "created", "", false},
+			ProtobufInfo{Type: "bytes", TagNumber: 6, Optional: true, Name: "created"},
 		},
 		{
 			`protobuf:"bytes,7,opt,name=user" json:"author,omitempty" col:"users_id" default:"true" fkey:"id" ftable:"users" nullable:"false" reltype:"one"`,
-			ProtobufInfo{"bytes", 7, true, false, false, "user", "", false},
+			ProtobufInfo{Type: "bytes", TagNumber: 7, Optional: true, Name: "user"},
 		},
 		{
 			`protobuf:"bytes,9,rep,name=votes" json:"votes,omitempty" col:"id" default:"false" fkey:"comments_id" ftable:"votes" reltype:"many"`,
-			ProtobufInfo{"bytes", 9, true, false, true, "votes", "", false},
+			ProtobufInfo{Type: "bytes", TagNumber: 9, Optional: true, Repeated: true, Name: "votes"},
 		},
 		{
 			`protobuf:"varint,11,opt,name=i_like,json=iLike,proto3" json:"iLike,omitempty" custom:"true" default:"true" nullable:"false"`,
-			ProtobufInfo{"varint", 11, true, false, false, "i_like", "", true},
+			ProtobufInfo{Type: "varint", TagNumber: 11, Optional: true, Name: "i_like", JSONName: "iLike", Proto3: true},
+		},
+		{
+			`protobuf:"varint,12,rep,packed,name=scores,proto3" json:"scores,omitempty"`,
+			ProtobufInfo{Type: "varint", TagNumber: 12, Optional: true, Repeated: true, Packed: true, Name: "scores", Proto3: true},
+		},
+		{
+			`protobuf:"bytes,13,opt,name=title,def=hello, world"`,
+			ProtobufInfo{Type: "bytes", TagNumber: 13, Optional: true, Name: "title", Default: "hello, world"},
+		},
+		{
+			`protobuf:"bytes,14,opt,name=nick,proto3,oneof"`,
+			ProtobufInfo{Type: "bytes", TagNumber: 14, Optional: true, Name: "nick", Proto3: true, Oneof: true},
 		},
 	}
 
@@ -46,7 +62,262 @@ func TestProtobufInfo(t *testing.T) {
 		}
 
 		if *pbInfo != tt.out {
-			t.Errorf("Error extracting info from\n%s\nExpected:\n\t%q\ngot:\n\t%q\n", tt.in, tt.out, *pbInfo)
+			t.Errorf("Error extracting info from\n%s\nExpected:\n\t%+v\ngot:\n\t%+v\n", tt.in, tt.out, *pbInfo)
+		}
+	}
+}
+
+func TestProtobufInfoString(t *testing.T) {
+	var tests = []string{
+		"bytes,4,opt,name=comment,proto3",
+		"varint,5,opt,name=type,proto3,enum=models.CommentType",
+		"bytes,7,opt,name=user",
+		"bytes,9,rep,name=votes",
+		"varint,1,req,name=id",
+		"fixed64,2,opt,name=amount",
+		"varint,12,rep,packed,name=scores,proto3",
+		"varint,11,opt,name=i_like,json=iLike,proto3",
+		"bytes,1,opt,name=name,proto3,oneof",
+		"varint,3,opt,name=state,enum=pkg.State,def=1",
+		"bytes,13,opt,name=title,def=hello, world",
+	}
+
+	for _, tt := range tests {
+		tag := TagString(`protobuf:"` + tt + `"`)
+		pbInfo := tag.ProtobufInfo()
+		if out := pbInfo.String(); out != tt {
+			t.Errorf("got %q from %q", out, tt)
+		}
+		if out := pbInfo.TagValue(); out.Name() != pbInfo.Type {
+			t.Errorf("got TagValue name %q from %q, expected %q", out.Name(), tt, pbInfo.Type)
+		}
+	}
+}
+
+func TestSetProtobufInfo(t *testing.T) {
+	var tag TagString = `json:"comment,omitempty"`
+	pbInfo := &ProtobufInfo{Type: "bytes", TagNumber: 4, Optional: true, Name: "comment", Proto3: true}
+
+	tag.SetProtobufInfo(pbInfo)
+	if tag != `json:"comment,omitempty" protobuf:"bytes,4,opt,name=comment,proto3"` {
+		t.Errorf("got %s", tag)
+	}
+
+	pbInfo = tag.ProtobufInfo()
+	pbInfo.TagNumber = 5
+	pbInfo.Name = "note"
+	tag.SetProtobufInfo(pbInfo)
+	if tag != `json:"comment,omitempty" protobuf:"bytes,5,opt,name=note,proto3"` {
+		t.Errorf("got %s after editing", tag)
+	}
+}
+
+// The following types are copied from protoc-gen-go output for:
+//
+//	syntax = "proto3";
+//	package accounts;
+//
+//	message Account {
+//	  int64 id = 1;
+//	  map<string, int32> scores = 2;
+//	  repeated sint64 deltas = 3;
+//	  oneof contact {
+//	    string email = 4;
+//	    uint64 phone = 5;
+//	  }
+//	}
+type testAccount struct {
+	Id     int64            `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Scores map[string]int32 `protobuf:"bytes,2,rep,name=scores,proto3" json:"scores,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"varint,2,opt,name=value,proto3"`
+	Deltas []int64          `protobuf:"zigzag64,3,rep,packed,name=deltas,proto3" json:"deltas,omitempty"`
+	// Types that are valid to be assigned to Contact:
+	//	*testAccount_Email
+	//	*testAccount_Phone
+	Contact              isTestAccount_Contact `protobuf_oneof:"contact"`
+	XXX_NoUnkeyedLiteral struct{}              `json:"-"`
+	XXX_unrecognized     []byte                `json:"-"`
+	XXX_sizecache        int32                 `json:"-"`
+}
+
+type isTestAccount_Contact interface {
+	isTestAccount_Contact()
+}
+
+type testAccount_Email struct {
+	Email string `protobuf:"bytes,4,opt,name=email,proto3,oneof"`
+}
+
+type testAccount_Phone struct {
+	Phone uint64 `protobuf:"varint,5,opt,name=phone,proto3,oneof"`
+}
+
+func (*testAccount_Email) isTestAccount_Contact() {}
+
+func (*testAccount_Phone) isTestAccount_Contact() {}
+
+// XXX_OneofWrappers is for the internal use of the proto package.
+func (*testAccount) XXX_OneofWrappers() []interface{} {
+	return []interface{}{
+		(*testAccount_Email)(nil),
+		(*testAccount_Phone)(nil),
+	}
+}
+
+func TestProtobufMapInfo(t *testing.T) {
+	field, _ := reflect.TypeOf(testAccount{}).FieldByName("Scores")
+	tag := TagString(field.Tag)
+
+	if pbInfo := tag.ProtobufInfo(); !pbInfo.Repeated || pbInfo.TagNumber != 2 {
+		t.Errorf("got %+v for the map field", pbInfo)
+	}
+	keyInfo := tag.ProtobufKeyInfo()
+	if keyInfo == nil || *keyInfo != (ProtobufInfo{Type: "bytes", TagNumber: 1, Optional: true, Name: "key", Proto3: true}) {
+		t.Errorf("got key info %+v", keyInfo)
+	}
+	valInfo := tag.ProtobufValInfo()
+	if valInfo == nil || *valInfo != (ProtobufInfo{Type: "varint", TagNumber: 2, Optional: true, Name: "value", Proto3: true}) {
+		t.Errorf("got value info %+v", valInfo)
+	}
+
+	var newTag TagString = `protobuf:"bytes,2,rep,name=scores,proto3" json:"scores,omitempty"`
+	newTag.SetProtobufMapInfo(keyInfo, valInfo)
+	if newTag != tag {
+		t.Errorf("got %s, expected %s", newTag, tag)
+	}
+
+	field, _ = reflect.TypeOf(testAccount{}).FieldByName("Id")
+	tag = TagString(field.Tag)
+	if tag.ProtobufKeyInfo() != nil || tag.ProtobufValInfo() != nil {
+		t.Error("Got map info for a non-map field")
+	}
+}
+
+func TestProtobufOneofs(t *testing.T) {
+	oneofs := ProtobufOneofs(reflect.TypeOf(&testAccount{}))
+	if len(oneofs) != 1 {
+		t.Fatalf("got %d oneofs, expected 1", len(oneofs))
+	}
+
+	oneof := oneofs[0]
+	if oneof.Name != "contact" || oneof.Field != "Contact" || len(oneof.Choices) != 2 {
+		t.Fatalf("got %+v", oneof)
+	}
+
+	expected := []struct {
+		wrapper reflect.Type
+		field   string
+		info    ProtobufInfo
+	}{
+		{reflect.TypeOf(&testAccount_Email{}), "Email", ProtobufInfo{Type: "bytes", TagNumber: 4, Optional: true, Name: "email", Proto3: true, Oneof: true}},
+		{reflect.TypeOf(&testAccount_Phone{}), "Phone", ProtobufInfo{Type: "varint", TagNumber: 5, Optional: true, Name: "phone", Proto3: true, Oneof: true}},
+	}
+	for i, choice := range oneof.Choices {
+		if choice.Wrapper != expected[i].wrapper || choice.Field != expected[i].field || *choice.Info != expected[i].info {
+			t.Errorf("got %v %s %+v, expected %+v", choice.Wrapper, choice.Field, *choice.Info, expected[i])
+		}
+	}
+
+	if oneofs := ProtobufOneofs(reflect.TypeOf(ProtobufInfo{})); oneofs != nil {
+		t.Errorf("got %+v from a struct without oneofs", oneofs)
+	}
+}
+
+// Oneofs protoc-gen-go never generates, on a field that isn't an interface
+// and with a wrapper that isn't a pointer
+type testBadOneofs struct {
+	Name    string                  `protobuf_oneof:"name"`
+	Contact isTestBadOneofs_Contact `protobuf_oneof:"contact"`
+}
+
+type isTestBadOneofs_Contact interface {
+	isTestBadOneofs_Contact()
+}
+
+type testBadOneofs_Value struct {
+	Value string `protobuf:"bytes,2,opt,name=value,proto3,oneof"`
+}
+
+func (testBadOneofs_Value) isTestBadOneofs_Contact() {}
+
+func (*testBadOneofs) XXX_OneofWrappers() []interface{} {
+	return []interface{}{testBadOneofs_Value{}}
+}
+
+func TestProtobufOneofsInvalid(t *testing.T) {
+	oneofs := ProtobufOneofs(reflect.TypeOf(testBadOneofs{}))
+	if len(oneofs) != 2 || oneofs[0].Choices != nil || oneofs[1].Choices != nil {
+		t.Fatalf("got %+v", oneofs)
+	}
+
+	const expected = "testBadOneofs.Name: oneof name must be an interface, got string"
+	msg := &testBadOneofs{Contact: testBadOneofs_Value{Value: "x"}}
+	if _, err := MarshalProtobuf(msg); err == nil || err.Error() != expected {
+		t.Errorf("got %v from MarshalProtobuf", err)
+	}
+	if _, err := DescribeMessageOf(msg); err == nil || err.Error() != expected {
+		t.Errorf("got %v from DescribeMessageOf", err)
+	}
+}
+
+func TestProtobufInfoGenerated(t *testing.T) {
+	field, _ := reflect.TypeOf(testAccount{}).FieldByName("Deltas")
+	tag := TagString(field.Tag)
+	pbInfo := tag.ProtobufInfo()
+	if *pbInfo != (ProtobufInfo{Type: "zigzag64", TagNumber: 3, Optional: true, Repeated: true, Packed: true, Name: "deltas", Proto3: true}) {
+		t.Errorf("got %+v", pbInfo)
+	}
+	if pbInfo.String() != tag.Get("protobuf") {
+		t.Errorf("got %q, expected %q", pbInfo.String(), tag.Get("protobuf"))
+	}
+}
+
+func TestParseProtobufInfo(t *testing.T) {
+	var tests = []struct {
+		in  string
+		err string
+	}{
+		{"bytes,1,opt,name=foo,proto3", ""},
+		{"group,536870911,rep", ""},
+		{"fixed32,18999,req", ""},
+		{"bytes,2,opt,name=foo,stdtime", ""},
+		{"", `missing tag number`},
+		{"bytes", `missing tag number`},
+		{"bytes,1", `missing cardinality`},
+		{"bytes,x,opt", `tag number "x" is not a number`},
+		{"bytes,0,opt", `tag number 0 is out of range 1 to 536870911`},
+		{"bytes,536870912,opt", `tag number 536870912 is out of range 1 to 536870911`},
+		{"bytes,19000,opt", `tag number 19000 is reserved for the protobuf implementation`},
+		{"bytes,19999,opt", `tag number 19999 is reserved for the protobuf implementation`},
+		{"string,1,opt", `unknown wire type "string"`},
+		{"bytes,1,optional", `unknown cardinality "optional", expected opt, req or rep`},
+		{"varint,1,opt,packed", `packed is only valid for repeated fields`},
+		{"bytes,1,rep,packed", `packed is not valid for wire type bytes`},
+	}
+
+	for _, tt := range tests {
+		pbInfo, err := ParseProtobufInfo(tt.in)
+		if tt.err == "" {
+			if err != nil || pbInfo == nil {
+				t.Errorf("got %v parsing %q, expected no error", err, tt.in)
+			}
+			continue
+		}
+
+		expected := "Invalid protobuf tag " + strconv.Quote(tt.in) + ": " + tt.err
+		if err == nil || err.Error() != expected {
+			t.Errorf("got %v parsing %q, expected %q", err, tt.in, expected)
+		}
+		if pbInfo != nil {
+			t.Errorf("got %+v along with an error parsing %q", pbInfo, tt.in)
+		}
+	}
+}
+
+// Malformed tags used to panic
+func TestProtobufInfoMalformed(t *testing.T) {
+	for _, tag := range []TagString{`protobuf:"bytes"`, `protobuf:"bytes,x,opt"`, `protobuf:"bytes,1"`, `json:"id"`} {
+		if pbInfo := tag.ProtobufInfo(); pbInfo != nil {
+			t.Errorf("got %+v from %s, expected nil", pbInfo, tag)
 		}
 	}
 }
@@ -0,0 +1,585 @@
+package utils
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+// Protobuf wire types
+const (
+	protobufVarint  = 0
+	protobufFixed64 = 1
+	protobufBytes   = 2
+	protobufFixed32 = 5
+)
+
+var errProtobufTruncated = errors.New("Truncated protobuf message")
+
+// MarshalProtobuf encodes the generated protobuf struct msg in the protobuf
+// wire format, using nothing but its protobuf tags
+// Varint, zigzag, fixed32, fixed64 and bytes encoded fields are supported,
+// including nested messages, maps, oneofs and repeated and packed fields, but
+// not groups or extensions. Map entries are written in key order, so the
+// output is deterministic. Unknown fields kept in XXX_unrecognized are
+// written last.
+func MarshalProtobuf(msg interface{}) ([]byte, error) {
+	v := reflect.ValueOf(msg)
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil, nil
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil, errors.New("Not a struct")
+	}
+	return appendProtobufMessage(nil, v)
+}
+
+// UnmarshalProtobuf decodes the protobuf wire format data into msg, which must
+// be a pointer to a generated protobuf struct
+// Fields are merged into msg like the protobuf runtime does. Unknown fields
+// are kept in XXX_unrecognized if msg has it, and skipped otherwise.
+func UnmarshalProtobuf(data []byte, msg interface{}) error {
+	v := reflect.ValueOf(msg)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return errors.New("Not a pointer to a struct")
+	}
+	return decodeProtobufMessage(data, v.Elem())
+}
+
+// protobufPlan is how a struct type is encoded, derived from its tags once
+type protobufPlan struct {
+	fields       []*protobufField // In tag number order
+	byNumber     map[int]*protobufField
+	byName       map[string]*protobufField // By JSON name and declared name
+	unrecognized int                       // The index of XXX_unrecognized, or -1
+}
+
+type protobufField struct {
+	name     string // The Go field name, for errors
+	jsonName string
+	index    int
+	info     *ProtobufInfo
+	wire     int
+	wrapper  reflect.Type   // The wrapper type of oneof fields
+	key, val *protobufField // The entry fields of map fields
+}
+
+// protobufPlans caches the plans by type
+var protobufPlans sync.Map
+
+func protobufPlanOf(typ reflect.Type) (*protobufPlan, error) {
+	if plan, ok := protobufPlans.Load(typ); ok {
+		return plan.(*protobufPlan), nil
+	}
+
+	plan := &protobufPlan{byNumber: map[int]*protobufField{}, byName: map[string]*protobufField{}, unrecognized: -1}
+	add := func(f *protobufField) error {
+		if other, ok := plan.byNumber[f.info.TagNumber]; ok {
+			return errors.New(typ.Name() + "." + f.name + ": tag number " + strconv.Itoa(f.info.TagNumber) + " is also used by " + other.name)
+		}
+		plan.byNumber[f.info.TagNumber] = f
+		plan.byName[f.jsonName] = f
+		if f.info.Name != "" {
+			plan.byName[f.info.Name] = f
+		}
+		plan.fields = append(plan.fields, f)
+		return nil
+	}
+
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		tag := TagString(field.Tag)
+		if field.Name == "XXX_unrecognized" && field.Type == reflect.TypeOf([]byte(nil)) {
+			plan.unrecognized = i
+			continue
+		}
+		pbTag, ok := tag.Lookup("protobuf")
+		if !ok || field.PkgPath != "" {
+			continue
+		}
+
+		f, err := newProtobufField(field.Name, i, pbTag, field.Type)
+		if err == nil && field.Type.Kind() == reflect.Map {
+			if f.key, err = newProtobufField(field.Name, 0, tag.Get("protobuf_key"), field.Type.Key()); err == nil {
+				f.val, err = newProtobufField(field.Name, 0, tag.Get("protobuf_val"), field.Type.Elem())
+			}
+		}
+		if err != nil {
+			return nil, errors.New(typ.Name() + "." + err.Error())
+		}
+		if err := add(f); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, oneof := range ProtobufOneofs(typ) {
+		if len(oneof.Choices) == 0 {
+			return nil, errNoOneofChoices(typ, oneof)
+		}
+		field, _ := typ.FieldByName(oneof.Field)
+		for _, choice := range oneof.Choices {
+			wrapperField, _ := choice.Wrapper.Elem().FieldByName(choice.Field)
+			f, err := newProtobufField(choice.Field, field.Index[0], wrapperField.Tag.Get("protobuf"), wrapperField.Type)
+			if err != nil {
+				return nil, errors.New(choice.Wrapper.Elem().Name() + "." + err.Error())
+			}
+			f.wrapper = choice.Wrapper
+			if err := add(f); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	sort.Slice(plan.fields, func(i, j int) bool {
+		return plan.fields[i].info.TagNumber < plan.fields[j].info.TagNumber
+	})
+
+	actual, _ := protobufPlans.LoadOrStore(typ, plan)
+	return actual.(*protobufPlan), nil
+}
+
+func newProtobufField(name string, index int, pbTag string, typ reflect.Type) (*protobufField, error) {
+	pbInfo, err := ParseProtobufInfo(pbTag)
+	if err != nil {
+		return nil, errors.New(name + ": " + err.Error())
+	}
+
+	f := &protobufField{name: name, jsonName: pbInfo.JSONFieldName(), index: index, info: pbInfo}
+	if f.jsonName == "" {
+		f.jsonName = ProtobufJSONName(SnakeCase(name))
+	}
+	switch pbInfo.Type {
+	case "varint", "zigzag32", "zigzag64":
+		f.wire = protobufVarint
+	case "fixed32":
+		f.wire = protobufFixed32
+	case "fixed64":
+		f.wire = protobufFixed64
+	case "bytes":
+		f.wire = protobufBytes
+	default:
+		return nil, errors.New(name + ": protobuf groups are not supported")
+	}
+
+	// Make sure the Go type can be encoded, so the encoder doesn't have to
+	if typ.Kind() != reflect.Map {
+		if pbInfo.Repeated && typ.Kind() == reflect.Slice {
+			typ = typ.Elem()
+		}
+		if _, err := protoTypeName("", &ProtobufInfo{Type: pbInfo.Type}, typ); err != nil {
+			return nil, errors.New(name + ": " + err.Error())
+		}
+	}
+	return f, nil
+}
+
+func appendProtobufMessage(buf []byte, v reflect.Value) ([]byte, error) {
+	plan, err := protobufPlanOf(v.Type())
+	if err != nil {
+		return nil, err
+	}
+
+	for _, f := range plan.fields {
+		fv := v.Field(f.index)
+
+		switch {
+		case f.wrapper != nil:
+			if fv.IsNil() || fv.Elem().Type() != f.wrapper || fv.Elem().IsNil() {
+				continue
+			}
+			buf, err = appendProtobufField(buf, f, fv.Elem().Elem().Field(0))
+
+		case f.key != nil:
+			for _, key := range sortedMapKeys(fv) {
+				var entry []byte
+				if entry, err = appendProtobufField(entry, f.key, key); err != nil {
+					return nil, err
+				}
+				if entry, err = appendProtobufField(entry, f.val, fv.MapIndex(key)); err != nil {
+					return nil, err
+				}
+				buf = appendProtobufTag(buf, f.info.TagNumber, protobufBytes)
+				buf = binary.AppendUvarint(buf, uint64(len(entry)))
+				buf = append(buf, entry...)
+			}
+
+		case f.info.Repeated && fv.Kind() == reflect.Slice:
+			if fv.Len() == 0 {
+				continue
+			}
+			if f.info.Packed {
+				var packed []byte
+				for i := 0; i < fv.Len(); i++ {
+					packed, _ = appendProtobufValue(packed, f, fv.Index(i))
+				}
+				buf = appendProtobufTag(buf, f.info.TagNumber, protobufBytes)
+				buf = binary.AppendUvarint(buf, uint64(len(packed)))
+				buf = append(buf, packed...)
+				continue
+			}
+			for i := 0; i < fv.Len() && err == nil; i++ {
+				buf, err = appendProtobufField(buf, f, fv.Index(i))
+			}
+
+		default:
+			if isProtobufUnset(fv) {
+				if f.info.Required {
+					return nil, errors.New("Required protobuf field " + v.Type().Name() + "." + f.name + " is not set")
+				}
+				continue
+			}
+			buf, err = appendProtobufField(buf, f, fv)
+		}
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if plan.unrecognized >= 0 {
+		buf = append(buf, v.Field(plan.unrecognized).Bytes()...)
+	}
+	return buf, nil
+}
+
+// isProtobufUnset reports whether a singular field is left out of the message
+// Pointers are unset when nil, scalars when they are zero like in proto3.
+func isProtobufUnset(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Ptr:
+		return v.IsNil()
+	case reflect.Slice:
+		return v.Len() == 0
+	case reflect.Struct:
+		return false
+	}
+	return v.IsZero()
+}
+
+func appendProtobufTag(buf []byte, number, wire int) []byte {
+	return binary.AppendUvarint(buf, uint64(number)<<3|uint64(wire))
+}
+
+func appendProtobufField(buf []byte, f *protobufField, v reflect.Value) ([]byte, error) {
+	return appendProtobufValue(appendProtobufTag(buf, f.info.TagNumber, f.wire), f, v)
+}
+
+// appendProtobufValue appends v without its tag
+func appendProtobufValue(buf []byte, f *protobufField, v reflect.Value) ([]byte, error) {
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			v = reflect.Zero(v.Type().Elem())
+		} else {
+			v = v.Elem()
+		}
+	}
+
+	switch f.info.Type {
+	case "varint":
+		switch v.Kind() {
+		case reflect.Bool:
+			if v.Bool() {
+				return append(buf, 1), nil
+			}
+			return append(buf, 0), nil
+		case reflect.Int, reflect.Int32, reflect.Int64:
+			// Negative numbers are sign extended to 64 bits
+			return binary.AppendUvarint(buf, uint64(v.Int())), nil
+		}
+		return binary.AppendUvarint(buf, v.Uint()), nil
+
+	case "zigzag32":
+		n := int32(v.Int())
+		return binary.AppendUvarint(buf, uint64(uint32(n<<1^n>>31))), nil
+
+	case "zigzag64":
+		n := v.Int()
+		return binary.AppendUvarint(buf, uint64(n<<1^n>>63)), nil
+
+	case "fixed32":
+		var u uint32
+		switch v.Kind() {
+		case reflect.Float32:
+			u = math.Float32bits(float32(v.Float()))
+		case reflect.Int32:
+			u = uint32(v.Int())
+		default:
+			u = uint32(v.Uint())
+		}
+		return binary.LittleEndian.AppendUint32(buf, u), nil
+
+	case "fixed64":
+		var u uint64
+		switch v.Kind() {
+		case reflect.Float64:
+			u = math.Float64bits(v.Float())
+		case reflect.Int64:
+			u = uint64(v.Int())
+		default:
+			u = v.Uint()
+		}
+		return binary.LittleEndian.AppendUint64(buf, u), nil
+	}
+
+	// bytes
+	switch v.Kind() {
+	case reflect.String:
+		buf = binary.AppendUvarint(buf, uint64(v.Len()))
+		return append(buf, v.String()...), nil
+	case reflect.Slice:
+		buf = binary.AppendUvarint(buf, uint64(v.Len()))
+		return append(buf, v.Bytes()...), nil
+	}
+	msg, err := appendProtobufMessage(nil, v)
+	if err != nil {
+		return nil, err
+	}
+	buf = binary.AppendUvarint(buf, uint64(len(msg)))
+	return append(buf, msg...), nil
+}
+
+// sortedMapKeys returns the keys of the map m in order
+func sortedMapKeys(m reflect.Value) []reflect.Value {
+	keys := m.MapKeys()
+	sort.Slice(keys, func(i, j int) bool {
+		switch keys[i].Kind() {
+		case reflect.String:
+			return keys[i].String() < keys[j].String()
+		case reflect.Bool:
+			return !keys[i].Bool() && keys[j].Bool()
+		case reflect.Int, reflect.Int32, reflect.Int64:
+			return keys[i].Int() < keys[j].Int()
+		}
+		return keys[i].Uint() < keys[j].Uint()
+	})
+	return keys
+}
+
+func decodeProtobufMessage(data []byte, v reflect.Value) error {
+	plan, err := protobufPlanOf(v.Type())
+	if err != nil {
+		return err
+	}
+
+	for len(data) > 0 {
+		start := data
+		key, n := binary.Uvarint(data)
+		if n <= 0 {
+			return errProtobufTruncated
+		}
+		data = data[n:]
+		number, wire := int(key>>3), int(key&7)
+
+		f := plan.byNumber[number]
+		if f == nil {
+			if n, err = skipProtobufValue(data, wire); err != nil {
+				return err
+			}
+			data = data[n:]
+			if plan.unrecognized >= 0 {
+				unrecognized := v.Field(plan.unrecognized)
+				unrecognized.SetBytes(append(unrecognized.Bytes(), start[:len(start)-len(data)]...))
+			}
+			continue
+		}
+
+		fv := v.Field(f.index)
+		repeated := f.info.Repeated && fv.Kind() == reflect.Slice && f.key == nil
+		packed := repeated && wire == protobufBytes && f.wire != protobufBytes
+		if wire != f.wire && !packed {
+			return errors.New("Protobuf field " + v.Type().Name() + "." + f.name + " has wire type " + strconv.Itoa(wire) + ", expected " + strconv.Itoa(f.wire))
+		}
+
+		switch {
+		case f.wrapper != nil:
+			wrapper := reflect.New(f.wrapper.Elem())
+			n, err = decodeProtobufValue(data, f, wrapper.Elem().Field(0))
+			fv.Set(wrapper)
+
+		case f.key != nil:
+			var entry []byte
+			if entry, n, err = readProtobufBytes(data); err != nil {
+				return err
+			}
+			if fv.IsNil() {
+				fv.Set(reflect.MakeMap(fv.Type()))
+			}
+			key := reflect.New(fv.Type().Key()).Elem()
+			val := reflect.New(fv.Type().Elem()).Elem()
+			err = decodeProtobufEntry(entry, f, key, val)
+			fv.SetMapIndex(key, val)
+
+		case packed:
+			var payload []byte
+			if payload, n, err = readProtobufBytes(data); err != nil {
+				return err
+			}
+			for len(payload) > 0 && err == nil {
+				elem := reflect.New(fv.Type().Elem()).Elem()
+				var m int
+				m, err = decodeProtobufValue(payload, f, elem)
+				payload = payload[m:]
+				fv.Set(reflect.Append(fv, elem))
+			}
+
+		case repeated:
+			elem := reflect.New(fv.Type().Elem()).Elem()
+			n, err = decodeProtobufValue(data, f, elem)
+			fv.Set(reflect.Append(fv, elem))
+
+		default:
+			n, err = decodeProtobufValue(data, f, fv)
+		}
+
+		if err != nil {
+			return err
+		}
+		data = data[n:]
+	}
+	return nil
+}
+
+// decodeProtobufEntry decodes a map entry into key and val
+func decodeProtobufEntry(data []byte, f *protobufField, key, val reflect.Value) error {
+	for len(data) > 0 {
+		tag, n := binary.Uvarint(data)
+		if n <= 0 {
+			return errProtobufTruncated
+		}
+		data = data[n:]
+
+		var err error
+		switch number, wire := int(tag>>3), int(tag&7); {
+		case number == 1 && wire == f.key.wire:
+			n, err = decodeProtobufValue(data, f.key, key)
+		case number == 2 && wire == f.val.wire:
+			n, err = decodeProtobufValue(data, f.val, val)
+		default:
+			n, err = skipProtobufValue(data, wire)
+		}
+		if err != nil {
+			return err
+		}
+		data = data[n:]
+	}
+	return nil
+}
+
+// decodeProtobufValue decodes a value without its tag into v and returns the
+// number of bytes read
+func decodeProtobufValue(data []byte, f *protobufField, v reflect.Value) (int, error) {
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		v = v.Elem()
+	}
+
+	switch f.info.Type {
+	case "varint", "zigzag32", "zigzag64":
+		x, n := binary.Uvarint(data)
+		if n <= 0 {
+			return 0, errProtobufTruncated
+		}
+		switch {
+		case f.info.Type == "zigzag32":
+			v.SetInt(int64(int32(uint32(x)>>1) ^ -int32(x&1)))
+		case f.info.Type == "zigzag64":
+			v.SetInt(int64(x>>1) ^ -int64(x&1))
+		case v.Kind() == reflect.Bool:
+			v.SetBool(x != 0)
+		case v.Kind() == reflect.Int || v.Kind() == reflect.Int32 || v.Kind() == reflect.Int64:
+			v.SetInt(int64(x))
+		default:
+			v.SetUint(x)
+		}
+		return n, nil
+
+	case "fixed32":
+		if len(data) < 4 {
+			return 0, errProtobufTruncated
+		}
+		u := binary.LittleEndian.Uint32(data)
+		switch v.Kind() {
+		case reflect.Float32:
+			v.SetFloat(float64(math.Float32frombits(u)))
+		case reflect.Int32:
+			v.SetInt(int64(int32(u)))
+		default:
+			v.SetUint(uint64(u))
+		}
+		return 4, nil
+
+	case "fixed64":
+		if len(data) < 8 {
+			return 0, errProtobufTruncated
+		}
+		u := binary.LittleEndian.Uint64(data)
+		switch v.Kind() {
+		case reflect.Float64:
+			v.SetFloat(math.Float64frombits(u))
+		case reflect.Int64:
+			v.SetInt(int64(u))
+		default:
+			v.SetUint(u)
+		}
+		return 8, nil
+	}
+
+	// bytes
+	payload, n, err := readProtobufBytes(data)
+	if err != nil {
+		return 0, err
+	}
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(string(payload))
+	case reflect.Slice:
+		v.SetBytes(append([]byte{}, payload...))
+	default:
+		err = decodeProtobufMessage(payload, v)
+	}
+	return n, err
+}
+
+// readProtobufBytes reads a length prefixed value
+func readProtobufBytes(data []byte) (payload []byte, n int, err error) {
+	size, n := binary.Uvarint(data)
+	if n <= 0 || size > uint64(len(data)-n) {
+		return nil, 0, errProtobufTruncated
+	}
+	return data[n : n+int(size)], n + int(size), nil
+}
+
+// skipProtobufValue returns the length of a value of an unknown field
+func skipProtobufValue(data []byte, wire int) (int, error) {
+	switch wire {
+	case protobufVarint:
+		_, n := binary.Uvarint(data)
+		if n <= 0 {
+			return 0, errProtobufTruncated
+		}
+		return n, nil
+	case protobufFixed64:
+		if len(data) < 8 {
+			return 0, errProtobufTruncated
+		}
+		return 8, nil
+	case protobufBytes:
+		_, n, err := readProtobufBytes(data)
+		return n, err
+	case protobufFixed32:
+		if len(data) < 4 {
+			return 0, errProtobufTruncated
+		}
+		return 4, nil
+	}
+	return 0, errors.New("Unsupported protobuf wire type " + strconv.Itoa(wire))
+}
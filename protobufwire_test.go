@@ -0,0 +1,201 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/hex"
+	"reflect"
+	"testing"
+)
+
+// The examples from the protobuf encoding guide
+type testEncoding struct {
+	A int32   `protobuf:"varint,1,opt,name=a,proto3" json:"a,omitempty"`
+	B string  `protobuf:"bytes,2,opt,name=b,proto3" json:"b,omitempty"`
+	C *testC  `protobuf:"bytes,3,opt,name=c,proto3" json:"c,omitempty"`
+	D []int32 `protobuf:"varint,4,rep,packed,name=d,proto3" json:"d,omitempty"`
+}
+
+type testC struct {
+	A int32 `protobuf:"varint,1,opt,name=a,proto3" json:"a,omitempty"`
+}
+
+// A oneof as generated by protoc-gen-go from google.golang.org/protobuf,
+// without the XXX_OneofWrappers method
+type testNoWrappers struct {
+	Id      int64                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Contact isTestAccount_Contact `protobuf_oneof:"contact"`
+}
+
+func TestMarshalProtobuf(t *testing.T) {
+	var tests = []struct {
+		msg interface{}
+		out string
+	}{
+		{&testEncoding{A: 150}, "089601"},
+		{&testEncoding{B: "testing"}, "120774657374696e67"},
+		{&testEncoding{C: &testC{A: 150}}, "1a03089601"},
+		{&testEncoding{D: []int32{3, 270, 86942}}, "2206038e029ea705"},
+		{&testEncoding{A: -1}, "08ffffffffffffffffff01"},
+		{&testEncoding{}, ""},
+		{&testAccount{Deltas: []int64{-1, 1}}, "1a020102"},
+		{&testAccount{Contact: &testAccount_Phone{}}, "2800"},
+		{&testAccount{Scores: map[string]int32{"b": 2, "a": 1}}, "12050a01611001" + "12050a01621002"},
+		{&testLegacy{Id: new(int64), Scores: []uint32{1}}, "0800" + "1a0101"},
+	}
+
+	for _, test := range tests {
+		data, err := MarshalProtobuf(test.msg)
+		if err != nil {
+			t.Errorf("got error %v from %+v", err, test.msg)
+			continue
+		}
+		if out := hex.EncodeToString(data); out != test.out {
+			t.Errorf("got %s from %+v, expected %s", out, test.msg, test.out)
+		}
+
+		msg := reflect.New(reflect.TypeOf(test.msg).Elem())
+		if err := UnmarshalProtobuf(data, msg.Interface()); err != nil {
+			t.Errorf("got error %v decoding %s", err, test.out)
+		} else if !reflect.DeepEqual(msg.Interface(), test.msg) {
+			t.Errorf("got %+v from %s, expected %+v", msg.Interface(), test.out, test.msg)
+		}
+	}
+}
+
+func TestProtobufRoundTrip(t *testing.T) {
+	name, ratio := "Ada", 0.5
+	msgs := []interface{}{
+		&testProfile{
+			Name:    "Ada",
+			Status:  3,
+			Account: &testAccount{Id: -42, Deltas: []int64{5, -5}, Contact: &testAccount_Email{Email: "ada@example.com"}},
+			History: []*testAccount{{Id: 1}, {Id: 2, Scores: map[string]int32{"x": -3}}},
+			Linked:  map[string]*testAccount{"friend": {Contact: &testAccount_Phone{Phone: 4711}}},
+			Avatar:  []byte{0, 1, 2},
+		},
+		&testLegacy{Id: new(int64), Title: &name, Ratio: &ratio, Scores: []uint32{1, 300}},
+	}
+
+	for _, msg := range msgs {
+		data, err := MarshalProtobuf(msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		decoded := reflect.New(reflect.TypeOf(msg).Elem())
+		if err := UnmarshalProtobuf(data, decoded.Interface()); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(decoded.Interface(), msg) {
+			t.Errorf("got %+v, expected %+v", decoded.Interface(), msg)
+		}
+	}
+}
+
+func TestUnmarshalProtobufUnknown(t *testing.T) {
+	// Unpacked repeated fields are accepted for packed fields
+	var legacy testLegacy
+	data, _ := hex.DecodeString("0801" + "1801" + "1802" + "3801" + "420161")
+	if err := UnmarshalProtobuf(data, &legacy); err != nil {
+		t.Fatal(err)
+	}
+	if *legacy.Id != 1 || !reflect.DeepEqual(legacy.Scores, []uint32{1, 2}) {
+		t.Errorf("got %+v", legacy)
+	}
+	if unknown := hex.EncodeToString(legacy.XXX_unrecognized); unknown != "3801420161" {
+		t.Errorf("got unrecognized %s, expected 3801420161", unknown)
+	}
+
+	// Unknown fields are written back
+	out, err := MarshalProtobuf(&legacy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected, _ := hex.DecodeString("0801" + "1a020102" + "3801420161"); !bytes.Equal(out, expected) {
+		t.Errorf("got %x, expected %x", out, expected)
+	}
+
+	// Skipped without XXX_unrecognized
+	var c testC
+	if err := UnmarshalProtobuf(data, &c); err != nil || c.A != 1 {
+		t.Errorf("got %+v, %v", c, err)
+	}
+}
+
+func TestProtobufErrors(t *testing.T) {
+	if _, err := MarshalProtobuf(1); err == nil {
+		t.Error("Expected an error marshaling a non-struct")
+	}
+	if err := UnmarshalProtobuf(nil, testEncoding{}); err == nil {
+		t.Error("Expected an error unmarshaling into a non-pointer")
+	}
+	if _, err := MarshalProtobuf(&testLegacy{}); err == nil || err.Error() != "Required protobuf field testLegacy.Id is not set" {
+		t.Errorf("got %v", err)
+	}
+
+	for _, in := range []string{"08", "0896", "1205746573", "1a02089601", "0d0000", "0b"} {
+		data, _ := hex.DecodeString(in)
+		if err := UnmarshalProtobuf(data, &testEncoding{}); err == nil {
+			t.Errorf("Expected an error decoding %s", in)
+		}
+	}
+
+	// Wrong wire type
+	data, _ := hex.DecodeString("0d00000000")
+	if err := UnmarshalProtobuf(data, &testEncoding{}); err == nil || err.Error() != "Protobuf field testEncoding.A has wire type 5, expected 0" {
+		t.Errorf("got %v", err)
+	}
+
+	type badType struct {
+		Id int8 `protobuf:"varint,1,opt,name=id,proto3"`
+	}
+	if _, err := MarshalProtobuf(badType{}); err == nil || err.Error() != "badType.Id: Go type int8 can't be encoded as varint" {
+		t.Errorf("got %v", err)
+	}
+}
+
+func TestProtobufOneofWithoutWrappers(t *testing.T) {
+	const expected = "testNoWrappers.Contact: can't find the choices of oneof contact, there is no XXX_OneofWrappers method"
+	msg := &testNoWrappers{Id: 1, Contact: &testAccount_Email{Email: "x"}}
+
+	if _, err := MarshalProtobuf(msg); err == nil || err.Error() != expected {
+		t.Errorf("got %v from MarshalProtobuf", err)
+	}
+	if err := UnmarshalProtobuf([]byte{0x22, 0x01, 'x'}, msg); err == nil || err.Error() != expected {
+		t.Errorf("got %v from UnmarshalProtobuf", err)
+	}
+	if _, err := ProtobufToMap(msg); err == nil || err.Error() != expected {
+		t.Errorf("got %v from ProtobufToMap", err)
+	}
+	if _, err := DescribeMessageOf(msg); err == nil || err.Error() != expected {
+		t.Errorf("got %v from DescribeMessageOf", err)
+	}
+	var buf bytes.Buffer
+	if err := GenerateProto(&buf, "", reflect.TypeOf(msg)); err == nil || err.Error() != expected {
+		t.Errorf("got %v from GenerateProto", err)
+	}
+}
+
+func BenchmarkMarshalProtobuf(b *testing.B) {
+	msg := &testProfile{
+		Name:    "Ada",
+		Account: &testAccount{Id: 42, Deltas: []int64{5, -5, 7}},
+		History: []*testAccount{{Id: 1}, {Id: 2}},
+		Avatar:  make([]byte, 64),
+	}
+	for n := 0; n < b.N; n++ {
+		MarshalProtobuf(msg)
+	}
+}
+
+func BenchmarkUnmarshalProtobuf(b *testing.B) {
+	data, _ := MarshalProtobuf(&testProfile{
+		Name:    "Ada",
+		Account: &testAccount{Id: 42, Deltas: []int64{5, -5, 7}},
+		History: []*testAccount{{Id: 1}, {Id: 2}},
+		Avatar:  make([]byte, 64),
+	})
+	for n := 0; n < b.N; n++ {
+		var msg testProfile
+		UnmarshalProtobuf(data, &msg)
+	}
+}
@@ -0,0 +1,102 @@
+package utils
+
+import "iter"
+
+// UniqueSeq yields the distinct values of seq in the order they first occur
+// Values are deduplicated lazily, so memory grows with the number of distinct
+// values and not with the length of seq. See UniqueSeqApprox for fixed memory.
+func UniqueSeq[T comparable](seq iter.Seq[T]) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		seen := map[T]struct{}{}
+		for val := range seq {
+			if _, ok := seen[val]; ok {
+				continue
+			}
+			seen[val] = struct{}{}
+			if !yield(val) {
+				return
+			}
+		}
+	}
+}
+
+// UniqueSeqApprox yields the values of seq with distinct keys, using a Bloom
+// filter sized for n keys instead of remembering every key
+// Memory is fixed, but a value may be dropped as a duplicate although its key
+// wasn't seen before, roughly with the given false positive rate once n keys
+// have been seen. Duplicates are never yielded.
+func UniqueSeqApprox[T any](seq iter.Seq[T], key func(T) string, n int, falsePositiveRate float64) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		filter := NewBloomFilter(n, falsePositiveRate)
+		for val := range seq {
+			if filter.TestAndAddString(key(val)) {
+				continue
+			}
+			if !yield(val) {
+				return
+			}
+		}
+	}
+}
+
+// FilterSeq yields the values of seq that keep returns true for
+func FilterSeq[T any](seq iter.Seq[T], keep func(T) bool) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for val := range seq {
+			if keep(val) && !yield(val) {
+				return
+			}
+		}
+	}
+}
+
+// MapSeq yields the results of calling f on each value of seq
+func MapSeq[T, U any](seq iter.Seq[T], f func(T) U) iter.Seq[U] {
+	return func(yield func(U) bool) {
+		for val := range seq {
+			if !yield(f(val)) {
+				return
+			}
+		}
+	}
+}
+
+// UniqueChan sends the distinct values received from in, in the order they
+// first occur, and closes the returned channel when in is closed
+// The returned channel must be drained, or the goroutine sending on it leaks.
+func UniqueChan[T comparable](in <-chan T) <-chan T {
+	return seqChan(UniqueSeq(chanSeq(in)))
+}
+
+// FilterChan sends the values received from in that keep returns true for,
+// see UniqueChan
+func FilterChan[T any](in <-chan T, keep func(T) bool) <-chan T {
+	return seqChan(FilterSeq(chanSeq(in), keep))
+}
+
+// MapChan sends the results of calling f on each value received from in, see
+// UniqueChan
+func MapChan[T, U any](in <-chan T, f func(T) U) <-chan U {
+	return seqChan(MapSeq(chanSeq(in), f))
+}
+
+func chanSeq[T any](ch <-chan T) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for val := range ch {
+			if !yield(val) {
+				return
+			}
+		}
+	}
+}
+
+func seqChan[T any](seq iter.Seq[T]) <-chan T {
+	out := make(chan T)
+	go func() {
+		defer close(out)
+		for val := range seq {
+			out <- val
+		}
+	}()
+	return out
+}
@@ -0,0 +1,97 @@
+package utils
+
+import (
+	"iter"
+	"reflect"
+	"slices"
+	"strconv"
+	"testing"
+)
+
+func TestUniqueSeq(t *testing.T) {
+	seq := UniqueSeq(slices.Values([]int{3, 1, 3, 2, 1, 4}))
+	if out := slices.Collect(seq); !reflect.DeepEqual(out, []int{3, 1, 2, 4}) {
+		t.Errorf("got %v", out)
+	}
+	// Every iteration starts over
+	if out := slices.Collect(seq); !reflect.DeepEqual(out, []int{3, 1, 2, 4}) {
+		t.Errorf("got %v on second iteration", out)
+	}
+}
+
+func TestUniqueSeqStops(t *testing.T) {
+	// An endless seq must stop when the consumer does
+	var naturals iter.Seq[int] = func(yield func(int) bool) {
+		for i := 0; ; i++ {
+			if !yield(i / 2) {
+				return
+			}
+		}
+	}
+
+	var out []int
+	for val := range UniqueSeq(naturals) {
+		if val == 3 {
+			break
+		}
+		out = append(out, val)
+	}
+	if !reflect.DeepEqual(out, []int{0, 1, 2}) {
+		t.Errorf("got %v", out)
+	}
+}
+
+func TestUniqueSeqApprox(t *testing.T) {
+	var vals []int
+	for i := 0; i < 1000; i++ {
+		vals = append(vals, i, i)
+	}
+	out := slices.Collect(UniqueSeqApprox(slices.Values(vals), strconv.Itoa, 1000, 0.01))
+
+	// Duplicates are never yielded, but a few distinct values may be dropped
+	if len(UniqueValues(out)) != len(out) {
+		t.Errorf("got duplicates in %v", out)
+	}
+	if len(out) < 950 || len(out) > 1000 {
+		t.Errorf("got %d of 1000 distinct values", len(out))
+	}
+}
+
+func TestFilterMapSeq(t *testing.T) {
+	evens := FilterSeq(slices.Values([]int{1, 2, 3, 4}), func(i int) bool { return i%2 == 0 })
+	out := slices.Collect(MapSeq(evens, strconv.Itoa))
+	if !reflect.DeepEqual(out, []string{"2", "4"}) {
+		t.Errorf("got %q", out)
+	}
+}
+
+func TestChan(t *testing.T) {
+	in := make(chan string)
+	go func() {
+		for _, str := range []string{"a", "bb", "a", "ccc", "bb", "dddd"} {
+			in <- str
+		}
+		close(in)
+	}()
+
+	short := FilterChan(UniqueChan(in), func(str string) bool { return len(str) < 4 })
+	var out []int
+	for n := range MapChan(short, func(str string) int { return len(str) }) {
+		out = append(out, n)
+	}
+	if !reflect.DeepEqual(out, []int{1, 2, 3}) {
+		t.Errorf("got %v", out)
+	}
+}
+
+func BenchmarkUniqueSeq(b *testing.B) {
+	vals := make([]int, 10000)
+	for i := range vals {
+		vals[i] = i % 1000
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for range UniqueSeq(slices.Values(vals)) {
+		}
+	}
+}
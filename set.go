@@ -0,0 +1,157 @@
+package utils
+
+import (
+	"cmp"
+	"slices"
+)
+
+// Set is a set of comparable values that remembers the order they were added in
+// The zero value is an empty set ready to use. A Set is not safe for
+// concurrent use.
+type Set[T comparable] struct {
+	index  map[T]int // The position of each value in values
+	values []T       // In insertion order, including removed values
+}
+
+// NewSet returns a set of vals, e.g. NewSet(slice...)
+func NewSet[T comparable](vals ...T) *Set[T] {
+	s := &Set[T]{index: make(map[T]int, len(vals))}
+	s.Add(vals...)
+	return s
+}
+
+// Add adds vals to the set
+// Values already in the set keep their position.
+func (s *Set[T]) Add(vals ...T) {
+	if s.index == nil {
+		s.index = make(map[T]int, len(vals))
+	}
+	for _, val := range vals {
+		if _, ok := s.index[val]; ok {
+			continue
+		}
+		s.index[val] = len(s.values)
+		s.values = append(s.values, val)
+	}
+}
+
+// Remove removes vals from the set
+func (s *Set[T]) Remove(vals ...T) {
+	for _, val := range vals {
+		delete(s.index, val)
+	}
+	// Removed values are left in values until they make up more than half of it
+	if len(s.values) > 2*len(s.index)+8 {
+		s.values = s.Values()
+		for i, val := range s.values {
+			s.index[val] = i
+		}
+	}
+}
+
+// Has reports whether val is in the set
+func (s *Set[T]) Has(val T) bool {
+	_, ok := s.index[val]
+	return ok
+}
+
+// HasAll reports whether all of vals are in the set
+func (s *Set[T]) HasAll(vals ...T) bool {
+	for _, val := range vals {
+		if !s.Has(val) {
+			return false
+		}
+	}
+	return true
+}
+
+// HasAny reports whether any of vals are in the set
+func (s *Set[T]) HasAny(vals ...T) bool {
+	for _, val := range vals {
+		if s.Has(val) {
+			return true
+		}
+	}
+	return false
+}
+
+// Missing returns the values of vals that are not in the set, in order
+func (s *Set[T]) Missing(vals ...T) (missing []T) {
+	for _, val := range vals {
+		if !s.Has(val) {
+			missing = append(missing, val)
+		}
+	}
+	return
+}
+
+// Len returns the number of values in the set
+func (s *Set[T]) Len() int {
+	return len(s.index)
+}
+
+// Values returns the values of the set in the order they were added
+func (s *Set[T]) Values() []T {
+	if len(s.index) == 0 {
+		return nil
+	}
+	vals := make([]T, 0, len(s.index))
+	for i, val := range s.values {
+		if j, ok := s.index[val]; ok && j == i {
+			vals = append(vals, val)
+		}
+	}
+	return vals
+}
+
+// SortedValues returns the values of s in sorted order
+func SortedValues[T cmp.Ordered](s *Set[T]) []T {
+	vals := s.Values()
+	slices.Sort(vals)
+	return vals
+}
+
+// Clone returns a copy of the set
+func (s *Set[T]) Clone() *Set[T] {
+	return NewSet(s.Values()...)
+}
+
+// Union returns the values in either set, those of s first
+func (s *Set[T]) Union(other *Set[T]) *Set[T] {
+	union := s.Clone()
+	union.Add(other.Values()...)
+	return union
+}
+
+// Intersect returns the values of s that are also in other
+func (s *Set[T]) Intersect(other *Set[T]) *Set[T] {
+	return NewSet(Filter(s.Values(), other.Has)...)
+}
+
+// Difference returns the values of s that are not in other
+func (s *Set[T]) Difference(other *Set[T]) *Set[T] {
+	return NewSet(other.Missing(s.Values()...)...)
+}
+
+// SymmetricDifference returns the values in only one of the sets, those of s
+// first
+func (s *Set[T]) SymmetricDifference(other *Set[T]) *Set[T] {
+	diff := s.Difference(other)
+	diff.Add(s.Missing(other.Values()...)...)
+	return diff
+}
+
+// IsSubset reports whether all values of s are in other
+func (s *Set[T]) IsSubset(other *Set[T]) bool {
+	return s.Len() <= other.Len() && other.HasAll(s.Values()...)
+}
+
+// IsSuperset reports whether all values of other are in s
+func (s *Set[T]) IsSuperset(other *Set[T]) bool {
+	return other.IsSubset(s)
+}
+
+// Equal reports whether the sets have the same values, in any order
+func (s *Set[T]) Equal(other *Set[T]) bool {
+	return s.Len() == other.Len() && s.IsSubset(other)
+}
@@ -0,0 +1,128 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSetValues(t *testing.T) {
+	s := NewSet("read", "write", "read", "admin")
+	if s.Len() != 3 || !reflect.DeepEqual(s.Values(), []string{"read", "write", "admin"}) {
+		t.Errorf("got %v", s.Values())
+	}
+	if !s.Has("write") || s.Has("delete") {
+		t.Error("Has is wrong")
+	}
+	if !s.HasAll("read", "admin") || s.HasAll("read", "delete") || !s.HasAll() {
+		t.Error("HasAll is wrong")
+	}
+	if !s.HasAny("delete", "admin") || s.HasAny("delete") || s.HasAny() {
+		t.Error("HasAny is wrong")
+	}
+	if missing := s.Missing("delete", "read", "owner"); !reflect.DeepEqual(missing, []string{"delete", "owner"}) {
+		t.Errorf("got %q from Missing", missing)
+	}
+	if sorted := SortedValues(s); !reflect.DeepEqual(sorted, []string{"admin", "read", "write"}) {
+		t.Errorf("got %q from SortedValues", sorted)
+	}
+
+	s.Remove("read", "delete")
+	s.Add("write", "read")
+	if s.Len() != 3 || !reflect.DeepEqual(s.Values(), []string{"write", "admin", "read"}) {
+		t.Errorf("got %v after remove and add", s.Values())
+	}
+
+	var zero Set[int]
+	if zero.Has(1) || zero.Len() != 0 || zero.Values() != nil {
+		t.Error("The zero set isn't empty")
+	}
+	zero.Add(2, 1)
+	if !reflect.DeepEqual(zero.Values(), []int{2, 1}) {
+		t.Errorf("got %v", zero.Values())
+	}
+}
+
+func TestSetRemoveMany(t *testing.T) {
+	s := NewSet[int]()
+	for i := 0; i < 1000; i++ {
+		s.Add(i)
+	}
+	for i := 0; i < 1000; i += 2 {
+		s.Remove(i)
+	}
+	s.Add(0)
+
+	vals := s.Values()
+	if len(vals) != 501 || vals[0] != 1 || vals[499] != 999 || vals[500] != 0 {
+		t.Errorf("got %d values %v...", len(vals), vals[:3])
+	}
+	if len(s.values) > 2*s.Len()+8 {
+		t.Errorf("%d removed values kept for %d values", len(s.values)-s.Len(), s.Len())
+	}
+}
+
+func TestSetAlgebra(t *testing.T) {
+	a := NewSet(1, 2, 3, 4)
+	b := NewSet(6, 4, 2, 5)
+
+	var tests = []struct {
+		name string
+		set  *Set[int]
+		out  []int
+	}{
+		{"Union", a.Union(b), []int{1, 2, 3, 4, 6, 5}},
+		{"Intersect", a.Intersect(b), []int{2, 4}},
+		{"Difference", a.Difference(b), []int{1, 3}},
+		{"Difference", b.Difference(a), []int{6, 5}},
+		{"SymmetricDifference", a.SymmetricDifference(b), []int{1, 3, 6, 5}},
+		{"Union with empty", a.Union(NewSet[int]()), []int{1, 2, 3, 4}},
+		{"Intersect with empty", a.Intersect(&Set[int]{}), nil},
+	}
+	for _, test := range tests {
+		if out := test.set.Values(); !reflect.DeepEqual(out, test.out) {
+			t.Errorf("got %v from %s, expected %v", out, test.name, test.out)
+		}
+	}
+
+	if !reflect.DeepEqual(a.Values(), []int{1, 2, 3, 4}) {
+		t.Errorf("The operations changed a to %v", a.Values())
+	}
+
+	sub := NewSet(4, 2)
+	if !sub.IsSubset(a) || !a.IsSuperset(sub) || a.IsSubset(sub) || !a.IsSubset(a) || b.IsSubset(a) {
+		t.Error("IsSubset is wrong")
+	}
+	if !a.Equal(NewSet(4, 3, 2, 1)) || a.Equal(b) || a.Equal(sub) {
+		t.Error("Equal is wrong")
+	}
+	if !NewSet[int]().IsSubset(a) {
+		t.Error("The empty set is a subset of every set")
+	}
+}
+
+func BenchmarkSetHas(b *testing.B) {
+	var strs []string
+	for i := 0; i < 1000; i++ {
+		strs = append(strs, "permission"+string(rune('a'+i%26))+string(rune('a'+i/26)))
+	}
+	s := NewSet(strs...)
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		_ = s.Has("permissionzz")
+	}
+}
+
+func BenchmarkStringInSlice(b *testing.B) {
+	var strs []string
+	for i := 0; i < 1000; i++ {
+		strs = append(strs, "permission"+string(rune('a'+i%26))+string(rune('a'+i/26)))
+	}
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		_ = StringInSlice("permissionzz", strs)
+	}
+}
@@ -0,0 +1,160 @@
+package utils
+
+import (
+	"cmp"
+	"slices"
+)
+
+// UniqueValues returns the distinct values of s in the order they first occur
+// It is the type safe version of Unique.
+func UniqueValues[T comparable](s []T) (unique []T) {
+	seen := map[T]struct{}{}
+	for _, val := range s {
+		if _, ok := seen[val]; ok {
+			continue
+		}
+		seen[val] = struct{}{}
+		unique = append(unique, val)
+	}
+	return
+}
+
+// UniqueLast returns the distinct values of s in the order they last occur
+func UniqueLast[T comparable](s []T) (unique []T) {
+	seen := map[T]struct{}{}
+	for i := len(s) - 1; i >= 0; i-- {
+		if _, ok := seen[s[i]]; ok {
+			continue
+		}
+		seen[s[i]] = struct{}{}
+		unique = append(unique, s[i])
+	}
+	slices.Reverse(unique)
+	return
+}
+
+// UniqueSorted returns the distinct values of s in sorted order
+// s is left untouched.
+func UniqueSorted[T cmp.Ordered](s []T) []T {
+	if len(s) == 0 {
+		return nil
+	}
+	sorted := slices.Clone(s)
+	slices.Sort(sorted)
+	return slices.Compact(sorted)
+}
+
+// UniqueBy returns the values of s with distinct keys, keeping the first value
+// for each key
+func UniqueBy[T any, K comparable](s []T, key func(T) K) (unique []T) {
+	seen := map[K]struct{}{}
+	for _, val := range s {
+		k := key(val)
+		if _, ok := seen[k]; ok {
+			continue
+		}
+		seen[k] = struct{}{}
+		unique = append(unique, val)
+	}
+	return
+}
+
+// Contains reports whether val is in s
+func Contains[T comparable](s []T, val T) bool {
+	return IndexOf(s, val) >= 0
+}
+
+// IndexOf returns the index of the first occurrence of val in s, or -1
+func IndexOf[T comparable](s []T, val T) int {
+	for i := range s {
+		if s[i] == val {
+			return i
+		}
+	}
+	return -1
+}
+
+// Filter returns the values of s that keep returns true for
+func Filter[T any](s []T, keep func(T) bool) (filtered []T) {
+	for _, val := range s {
+		if keep(val) {
+			filtered = append(filtered, val)
+		}
+	}
+	return
+}
+
+// Map returns the results of calling f on each value of s
+func Map[T, U any](s []T, f func(T) U) []U {
+	if s == nil {
+		return nil
+	}
+	mapped := make([]U, len(s))
+	for i, val := range s {
+		mapped[i] = f(val)
+	}
+	return mapped
+}
+
+// Reduce folds the values of s into a single value, starting from initial
+func Reduce[T, A any](s []T, initial A, f func(A, T) A) A {
+	acc := initial
+	for _, val := range s {
+		acc = f(acc, val)
+	}
+	return acc
+}
+
+// GroupBy groups the values of s by key, keeping their order within each group
+func GroupBy[T any, K comparable](s []T, key func(T) K) map[K][]T {
+	groups := map[K][]T{}
+	for _, val := range s {
+		k := key(val)
+		groups[k] = append(groups[k], val)
+	}
+	return groups
+}
+
+// Partition splits s into the values pred returns true and false for
+func Partition[T any](s []T, pred func(T) bool) (yes, no []T) {
+	for _, val := range s {
+		if pred(val) {
+			yes = append(yes, val)
+		} else {
+			no = append(no, val)
+		}
+	}
+	return
+}
+
+// Chunk splits s into slices of size values, the last one may be shorter
+// The chunks share the backing array of s. Chunk panics if size is less than 1.
+func Chunk[T any](s []T, size int) (chunks [][]T) {
+	if size < 1 {
+		panic("utils.Chunk: size must be at least 1")
+	}
+	for len(s) > size {
+		chunks = append(chunks, s[:size:size])
+		s = s[size:]
+	}
+	if len(s) > 0 {
+		chunks = append(chunks, s)
+	}
+	return
+}
+
+// Flatten concatenates the slices of s
+func Flatten[T any](s [][]T) []T {
+	n := 0
+	for _, inner := range s {
+		n += len(inner)
+	}
+	if n == 0 {
+		return nil
+	}
+	flat := make([]T, 0, n)
+	for _, inner := range s {
+		flat = append(flat, inner...)
+	}
+	return flat
+}
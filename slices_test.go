@@ -0,0 +1,162 @@
+package utils
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestUniqueValues(t *testing.T) {
+	if out := UniqueValues([]int{3, 1, 3, 2, 1}); !reflect.DeepEqual(out, []int{3, 1, 2}) {
+		t.Errorf("got %v, expected [3 1 2]", out)
+	}
+	if out := UniqueValues([]string{"b", "a", "b"}); !reflect.DeepEqual(out, []string{"b", "a"}) {
+		t.Errorf("got %q, expected [b a]", out)
+	}
+	if out := UniqueValues([]int{}); out != nil {
+		t.Errorf("got %v from an empty slice", out)
+	}
+}
+
+func TestUniqueLastSorted(t *testing.T) {
+	ints := []int{3, 1, 3, 2, 1}
+	if out := UniqueLast(ints); !reflect.DeepEqual(out, []int{3, 2, 1}) {
+		t.Errorf("got %v from UniqueLast, expected [3 2 1]", out)
+	}
+	if out := UniqueSorted(ints); !reflect.DeepEqual(out, []int{1, 2, 3}) {
+		t.Errorf("got %v from UniqueSorted, expected [1 2 3]", out)
+	}
+	if !reflect.DeepEqual(ints, []int{3, 1, 3, 2, 1}) {
+		t.Errorf("UniqueSorted changed its input to %v", ints)
+	}
+	if out := UniqueSorted([]string{"b", "a", "b"}); !reflect.DeepEqual(out, []string{"a", "b"}) {
+		t.Errorf("got %q from UniqueSorted", out)
+	}
+	if UniqueLast([]int{}) != nil || UniqueSorted([]int{}) != nil {
+		t.Error("Expected nil for empty input")
+	}
+}
+
+func TestUniqueBy(t *testing.T) {
+	words := []string{"apple", "Avocado", "banana", "Blueberry", "cherry"}
+	out := UniqueBy(words, func(w string) byte { return strings.ToLower(w)[0] })
+	if !reflect.DeepEqual(out, []string{"apple", "banana", "cherry"}) {
+		t.Errorf("got %q", out)
+	}
+}
+
+func TestContainsIndexOf(t *testing.T) {
+	strs := []string{"a", "b", "c", "b"}
+	if !Contains(strs, "c") || Contains(strs, "d") || Contains(nil, "a") {
+		t.Error("Contains is wrong")
+	}
+	if IndexOf(strs, "b") != 1 || IndexOf(strs, "d") != -1 {
+		t.Error("IndexOf is wrong")
+	}
+	if !StringInSlice("a", strs) || StringInSlice("A", strs) {
+		t.Error("StringInSlice is wrong")
+	}
+}
+
+func TestFilterMapReduce(t *testing.T) {
+	ints := []int{1, 2, 3, 4, 5}
+	even := Filter(ints, func(i int) bool { return i%2 == 0 })
+	if !reflect.DeepEqual(even, []int{2, 4}) {
+		t.Errorf("got %v from Filter", even)
+	}
+
+	strs := Map(ints, strconv.Itoa)
+	if !reflect.DeepEqual(strs, []string{"1", "2", "3", "4", "5"}) {
+		t.Errorf("got %q from Map", strs)
+	}
+	if Map[int, string](nil, strconv.Itoa) != nil {
+		t.Error("Map of nil should be nil")
+	}
+
+	sum := Reduce(ints, 0, func(acc, i int) int { return acc + i })
+	joined := Reduce(strs, "", func(acc, s string) string { return acc + s })
+	if sum != 15 || joined != "12345" {
+		t.Errorf("got %d and %q from Reduce", sum, joined)
+	}
+}
+
+func TestGroupByPartition(t *testing.T) {
+	words := []string{"go", "rust", "c", "zig", "java", "d"}
+	groups := GroupBy(words, func(w string) int { return len(w) })
+	expected := map[int][]string{1: {"c", "d"}, 2: {"go"}, 3: {"zig"}, 4: {"rust", "java"}}
+	if !reflect.DeepEqual(groups, expected) {
+		t.Errorf("got %v, expected %v", groups, expected)
+	}
+
+	short, long := Partition(words, func(w string) bool { return len(w) < 3 })
+	if !reflect.DeepEqual(short, []string{"go", "c", "d"}) || !reflect.DeepEqual(long, []string{"rust", "zig", "java"}) {
+		t.Errorf("got %q and %q from Partition", short, long)
+	}
+}
+
+func TestChunkFlatten(t *testing.T) {
+	ints := []int{1, 2, 3, 4, 5, 6, 7}
+	var tests = []struct {
+		size int
+		out  [][]int
+	}{
+		{3, [][]int{{1, 2, 3}, {4, 5, 6}, {7}}},
+		{7, [][]int{{1, 2, 3, 4, 5, 6, 7}}},
+		{10, [][]int{{1, 2, 3, 4, 5, 6, 7}}},
+		{1, [][]int{{1}, {2}, {3}, {4}, {5}, {6}, {7}}},
+	}
+	for _, test := range tests {
+		chunks := Chunk(ints, test.size)
+		if !reflect.DeepEqual(chunks, test.out) {
+			t.Errorf("got %v from size %d, expected %v", chunks, test.size, test.out)
+		}
+		if flat := Flatten(chunks); !reflect.DeepEqual(flat, ints) {
+			t.Errorf("got %v flattening %v", flat, chunks)
+		}
+	}
+
+	// Appending to a chunk must not overwrite the next one
+	chunks := Chunk(ints, 3)
+	_ = append(chunks[0], 0)
+	if chunks[1][0] != 4 {
+		t.Error("Chunks share capacity")
+	}
+
+	if Chunk([]int{}, 2) != nil || Flatten([][]int{{}, nil}) != nil {
+		t.Error("Expected nil for empty input")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected a panic for size 0")
+		}
+	}()
+	Chunk(ints, 0)
+}
+
+func BenchmarkUniqueSortedLargeManyUniques(b *testing.B) {
+	var ints []int
+	for i := 0; i < 100000; i++ {
+		ints = append(ints, i%10000)
+	}
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		_ = UniqueSorted(ints)
+	}
+}
+
+func BenchmarkUniqueLastLargeManyUniques(b *testing.B) {
+	var ints []int
+	for i := 0; i < 100000; i++ {
+		ints = append(ints, i%10000)
+	}
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		_ = UniqueLast(ints)
+	}
+}
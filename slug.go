@@ -0,0 +1,150 @@
+package utils
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// SlugOptions controls how SlugWithOptions builds a slug
+type SlugOptions struct {
+	// Transliterate replaces Latin-extended, Greek and Cyrillic letters with
+	// ASCII and drops every other non-ASCII letter, giving URL safe output
+	Transliterate bool
+
+	// MaxLength is the maximum length of the slug in bytes
+	// The slug is cut at a word boundary. 0 means no limit.
+	MaxLength int
+
+	// Separator is put between words, "-" if empty
+	Separator string
+
+	// Lowercase lower cases the slug
+	Lowercase bool
+
+	// Exists is called with a candidate slug and should report whether it is
+	// already taken. If so, a numeric suffix is added: "slug-2", "slug-3" etc.
+	// The slug is cut to make room for the suffix within MaxLength. If every
+	// suffix that fits is taken, the last candidate is returned although it
+	// exists.
+	Exists func(slug string) bool
+}
+
+// DefaultSlugOptions gives URL safe, lower cased slugs separated by dashes
+var DefaultSlugOptions = SlugOptions{
+	Transliterate: true,
+	Lowercase:     true,
+}
+
+// SlugWithOptions converts str to a slug as described by opts
+// E.g. "Ærø Café" becomes "aero-cafe" with DefaultSlugOptions.
+func SlugWithOptions(str string, opts SlugOptions) string {
+	sep := opts.Separator
+	if sep == "" {
+		sep = "-"
+	}
+
+	words := DefaultConverter.Words(str)
+	n := 0
+	for _, word := range words {
+		if opts.Transliterate {
+			word = asciiAlnum(Transliterate(word))
+		}
+		if opts.Lowercase {
+			word = strings.ToLower(word)
+		}
+		if word != "" {
+			words[n] = word
+			n++
+		}
+	}
+	words = words[:n]
+
+	slug := joinWords(words, sep, opts.MaxLength)
+	if opts.Exists == nil || !opts.Exists(slug) {
+		return slug
+	}
+
+	last := slug
+	for i := 2; ; i++ {
+		suffix := strconv.Itoa(i)
+		base, baseSep := slug, sep
+		if opts.MaxLength > 0 {
+			// Make room for the suffix, leaving out the separator if that's too
+			// long as well
+			if limit := opts.MaxLength - len(sep) - len(suffix); limit > 0 {
+				base = joinWords(words, sep, limit)
+			} else if limit = opts.MaxLength - len(suffix); limit >= 0 {
+				baseSep = ""
+				if limit < len(base) {
+					base = truncateRunes(base, limit)
+				}
+			} else {
+				// Not even the suffix fits
+				return last
+			}
+		}
+
+		candidate := suffix
+		if base != "" {
+			candidate = base + baseSep + suffix
+		}
+		if !opts.Exists(candidate) {
+			return candidate
+		}
+		last = candidate
+	}
+}
+
+// joinWords joins as many words as fit within maxLength bytes
+// If not even the first word fits, it is cut at maxLength. A maxLength of 0
+// means no limit.
+func joinWords(words []string, sep string, maxLength int) string {
+	if len(words) == 0 {
+		return ""
+	}
+
+	var buf strings.Builder
+	for i, word := range words {
+		if i == 0 {
+			if maxLength > 0 && len(word) > maxLength {
+				return truncateRunes(word, maxLength)
+			}
+		} else {
+			if maxLength > 0 && buf.Len()+len(sep)+len(word) > maxLength {
+				break
+			}
+			buf.WriteString(sep)
+		}
+		buf.WriteString(word)
+	}
+	return buf.String()
+}
+
+// truncateRunes cuts str to at most n bytes without splitting a rune
+func truncateRunes(str string, n int) string {
+	for n > 0 && !utf8.RuneStart(str[n]) {
+		n--
+	}
+	return str[:n]
+}
+
+// asciiAlnum removes everything but ASCII letters and digits from str
+func asciiAlnum(str string) string {
+	for i := 0; i < len(str); i++ {
+		if !isAlnum(str[i]) {
+			return strings.Map(func(r rune) rune {
+				if r < utf8.RuneSelf && isAlnum(byte(r)) {
+					return r
+				}
+				return -1
+			}, str)
+		}
+	}
+	return str
+}
+
+func isAlnum(b byte) bool { return isUpper(b) || isLower(b) || isDigit(b) }
+func isUpper(b byte) bool { return b >= 'A' && b <= 'Z' }
+func isLower(b byte) bool { return b >= 'a' && b <= 'z' }
+func isDigit(b byte) bool { return b >= '0' && b <= '9' }
@@ -0,0 +1,116 @@
+package utils
+
+import "testing"
+
+func TestSlugWithOptions(t *testing.T) {
+	samples := []sample{
+		{"Ærø Café", "aero-cafe"},
+		{"Größe Straße", "groesse-strasse"},
+		{"ÆRØ", "aero"},
+		{"Αθήνα", "athina"},
+		{"Москва Сити", "moskva-siti"},
+		{"Tiếng Việt", "tieng-viet"},
+		{"Đà Nẵng", "da-nang"},
+		{"Ǆemal Ǉubović", "dzemal-ljubovic"},
+		{"Café", "cafe"},
+		{"日本語 テキスト", ""},
+		{"東京Tokyo 2020", "tokyo-2020"},
+		{"inviteYourCustomersAddInvites", "invite-your-customers-add-invites"},
+		{"   $#$sample   2    Text   ", "sample-2-text"},
+	}
+
+	for _, sample := range samples {
+		if out := SlugWithOptions(sample.str, DefaultSlugOptions); out != sample.out {
+			t.Errorf("got %q from %q, expected %q", out, sample.str, sample.out)
+		}
+	}
+}
+
+func TestSlugWithOptionsNoTransliteration(t *testing.T) {
+	opts := SlugOptions{Separator: "_"}
+	if out := SlugWithOptions("Ærø Café", opts); out != "Ærø_Café" {
+		t.Errorf("got %q, expected %q", out, "Ærø_Café")
+	}
+
+	opts.Lowercase = true
+	if out := SlugWithOptions("Ærø Café", opts); out != "ærø_café" {
+		t.Errorf("got %q, expected %q", out, "ærø_café")
+	}
+}
+
+func TestSlugWithOptionsMaxLength(t *testing.T) {
+	opts := DefaultSlugOptions
+	samples := []struct {
+		str       string
+		maxLength int
+		out       string
+	}{
+		{"the quick brown fox", 0, "the-quick-brown-fox"},
+		{"the quick brown fox", 15, "the-quick-brown"},
+		{"the quick brown fox", 14, "the-quick"},
+		{"the quick brown fox", 3, "the"},
+		{"the quick brown fox", 2, "th"},
+		{"supercalifragilistic", 5, "super"},
+	}
+
+	for _, sample := range samples {
+		opts.MaxLength = sample.maxLength
+		if out := SlugWithOptions(sample.str, opts); out != sample.out {
+			t.Errorf("got %q from %q with MaxLength %d, expected %q", out, sample.str, sample.maxLength, sample.out)
+		}
+	}
+
+	opts = SlugOptions{MaxLength: 4}
+	if out := SlugWithOptions("ÆØÅ", opts); out != "ÆØ" {
+		t.Errorf("got %q, expected %q - runes should not be split", out, "ÆØ")
+	}
+}
+
+func TestSlugWithOptionsExists(t *testing.T) {
+	taken := map[string]bool{
+		"aero-cafe":   true,
+		"aero-cafe-2": true,
+		"aero-2":      true,
+	}
+	opts := DefaultSlugOptions
+	opts.Exists = func(slug string) bool { return taken[slug] }
+
+	if out := SlugWithOptions("Ærø Café", opts); out != "aero-cafe-3" {
+		t.Errorf("got %q, expected %q", out, "aero-cafe-3")
+	}
+	if out := SlugWithOptions("Ærø Bar", opts); out != "aero-bar" {
+		t.Errorf("got %q, expected %q", out, "aero-bar")
+	}
+
+	// The suffix must fit within MaxLength
+	opts.MaxLength = 10
+	if out := SlugWithOptions("Ærø Café", opts); out != "aero-3" {
+		t.Errorf("got %q, expected %q", out, "aero-3")
+	}
+
+	// Without room for the separator, the slug is cut instead of dropped
+	opts.MaxLength = 2
+	taken["ae"] = true
+	if out := SlugWithOptions("Ærø Café", opts); out != "a2" {
+		t.Errorf("got %q, expected %q", out, "a2")
+	}
+
+	// Candidates never exceed MaxLength, even when they are all taken
+	var last string
+	opts.Exists = func(slug string) bool {
+		if len(slug) > opts.MaxLength {
+			t.Fatalf("got candidate %q longer than %d", slug, opts.MaxLength)
+		}
+		last = slug
+		return true
+	}
+	if out := SlugWithOptions("Ærø Café", opts); out != "99" || last != "99" {
+		t.Errorf("got %q, expected %q", out, "99")
+	}
+}
+
+func BenchmarkSlugWithOptions(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		_ = SlugWithOptions("Ærø Café og Größe Straße", DefaultSlugOptions)
+	}
+}
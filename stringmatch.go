@@ -0,0 +1,94 @@
+package utils
+
+import (
+	"sort"
+	"strings"
+)
+
+// StringInSliceFold reports whether searchStr is in strs, ignoring case
+// Strings are compared with Unicode case folding, see strings.EqualFold.
+func StringInSliceFold(searchStr string, strs []string) bool {
+	for _, str := range strs {
+		if strings.EqualFold(searchStr, str) {
+			return true
+		}
+	}
+	return false
+}
+
+// StringInSliceNormalized reports whether searchStr is in strs when both are
+// converted to snake_case, so "userId", "UserID" and "user-id" all match
+// "user_id"
+func StringInSliceNormalized(searchStr string, strs []string) bool {
+	searchStr = SnakeCase(searchStr)
+	for _, str := range strs {
+		if SnakeCase(str) == searchStr {
+			return true
+		}
+	}
+	return false
+}
+
+// Levenshtein returns the number of single rune insertions, deletions and
+// substitutions needed to turn a into b
+func Levenshtein(a, b string) int {
+	return editDistance([]rune(a), []rune(b), false)
+}
+
+// DamerauLevenshtein returns the number of single rune insertions, deletions,
+// substitutions and transpositions of adjacent runes needed to turn a into b
+// This is the optimal string alignment distance, where no substring is edited
+// more than once.
+func DamerauLevenshtein(a, b string) int {
+	return editDistance([]rune(a), []rune(b), true)
+}
+
+func editDistance(a, b []rune, transpose bool) int {
+	// Three rows of the distance matrix are enough
+	prev2 := make([]int, len(b)+1)
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+			if transpose && i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				cur[j] = min(cur[j], prev2[j-2]+1)
+			}
+		}
+		prev2, prev, cur = prev, cur, prev2
+	}
+	return prev[len(b)]
+}
+
+// DidYouMean returns the candidates within maxDistance of input, closest first,
+// for suggesting valid values in error messages
+// Case is ignored and the distance is DamerauLevenshtein. Candidates at the
+// same distance keep their order.
+func DidYouMean(input string, candidates []string, maxDistance int) []string {
+	type match struct {
+		candidate string
+		distance  int
+	}
+
+	input = strings.ToLower(input)
+	var matches []match
+	for _, candidate := range candidates {
+		if distance := DamerauLevenshtein(input, strings.ToLower(candidate)); distance <= maxDistance {
+			matches = append(matches, match{candidate, distance})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].distance < matches[j].distance
+	})
+
+	return Map(matches, func(m match) string { return m.candidate })
+}
@@ -0,0 +1,89 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestStringInSliceFold(t *testing.T) {
+	strs := []string{"JSON", "Straße", "Ærø"}
+	for _, str := range []string{"json", "Json", "straße", "STRAẞE", "ærø", "ÆRØ"} {
+		if !StringInSliceFold(str, strs) {
+			t.Errorf("%q not found", str)
+		}
+	}
+	// Folding is rune by rune, so ß doesn't match ss
+	for _, str := range []string{"xml", "STRASSE"} {
+		if StringInSliceFold(str, strs) {
+			t.Errorf("Found %q", str)
+		}
+	}
+}
+
+func TestStringInSliceNormalized(t *testing.T) {
+	strs := []string{"user_id", "created_at"}
+	for _, str := range []string{"userId", "UserID", "user-id", "USER_ID", "createdAt", "Created At"} {
+		if !StringInSliceNormalized(str, strs) {
+			t.Errorf("%q not found", str)
+		}
+	}
+	if StringInSliceNormalized("userid", strs) {
+		t.Error("Found userid")
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	var tests = []struct {
+		a, b                 string
+		levenshtein, damerau int
+	}{
+		{"", "", 0, 0},
+		{"abc", "", 3, 3},
+		{"", "abc", 3, 3},
+		{"kitten", "sitting", 3, 3},
+		{"flaw", "lawn", 2, 2},
+		{"ab", "ba", 2, 1},
+		{"verbose", "vebrose", 2, 1},
+		{"ca", "abc", 3, 3},
+		{"gröẞe", "größe", 1, 1},
+		{"日本語", "日語本", 2, 1},
+	}
+
+	for _, test := range tests {
+		if out := Levenshtein(test.a, test.b); out != test.levenshtein {
+			t.Errorf("got Levenshtein %d from %q and %q, expected %d", out, test.a, test.b, test.levenshtein)
+		}
+		if out := DamerauLevenshtein(test.a, test.b); out != test.damerau {
+			t.Errorf("got DamerauLevenshtein %d from %q and %q, expected %d", out, test.a, test.b, test.damerau)
+		}
+	}
+}
+
+func TestDidYouMean(t *testing.T) {
+	flags := []string{"verbose", "version", "output", "force", "format"}
+
+	var tests = []struct {
+		in  string
+		max int
+		out []string
+	}{
+		{"vebrose", 2, []string{"verbose"}},
+		{"VERSOIN", 2, []string{"version"}},
+		{"forma", 2, []string{"format", "force"}},
+		{"fromat", 1, []string{"format"}},
+		{"output", 0, []string{"output"}},
+		{"xyz", 2, nil},
+	}
+
+	for _, test := range tests {
+		if out := DidYouMean(test.in, flags, test.max); !reflect.DeepEqual(out, test.out) {
+			t.Errorf("got %q from %q, expected %q", out, test.in, test.out)
+		}
+	}
+}
+
+func BenchmarkDamerauLevenshtein(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		_ = DamerauLevenshtein("inviteYourCustomersAddInvites", "inviteYuorCustomerAddInvite")
+	}
+}
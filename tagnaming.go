@@ -0,0 +1,59 @@
+package utils
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+)
+
+// TagNaming maps tag keys to the naming strategy used for their values, e.g.
+//
+//	TagNaming{"json": CamelCaseNaming, "db": SnakeCaseNaming}
+type TagNaming map[string]NamingStrategy
+
+// FieldTag is the tag generated for a single struct field
+type FieldTag struct {
+	Field string
+	Tag   TagString
+}
+
+// Apply adds the tags named by TagNaming for the Go field name to tag
+// Tags that already exist are left untouched. The result is sorted, so the
+// json tag comes first.
+func (naming TagNaming) Apply(field string, tag TagString) TagString {
+	keys := make([]string, 0, len(naming))
+	for key := range naming {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if _, ok := tag.Lookup(key); !ok {
+			tag.Add(key, naming[key].Name(field))
+		}
+	}
+	tag.Sort()
+	return tag
+}
+
+// GenerateTags returns the tags of the exported fields of typ, merged with the
+// tags generated by naming
+// typ must be a struct or a pointer to one. Embedded structs are skipped, as
+// their fields are generally promoted.
+func GenerateTags(typ reflect.Type, naming TagNaming) (tags []FieldTag, err error) {
+	for typ != nil && typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ == nil || typ.Kind() != reflect.Struct {
+		return nil, errors.New("Not a struct")
+	}
+
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if field.Anonymous || !field.IsExported() {
+			continue
+		}
+		tags = append(tags, FieldTag{field.Name, naming.Apply(field.Name, TagString(field.Tag))})
+	}
+	return
+}
@@ -0,0 +1,57 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+)
+
+type tagNamingUser struct {
+	fieldMapperBase
+	UserID    int
+	Email     string `json:"mail,omitempty"`
+	Password  string `json:"-" db:"password_hash"`
+	CreatedAt string `xml:"created"`
+	internal  string
+}
+
+func TestTagNamingApply(t *testing.T) {
+	naming := TagNaming{"json": CamelCaseNaming, "db": SnakeCaseNaming, "yaml": SnakeCaseNaming}
+	samples := []struct {
+		field string
+		in    TagString
+		out   TagString
+	}{
+		{"UserID", ``, `json:"userID" db:"user_id" yaml:"user_id"`},
+		{"UserID", `yaml:"uid"`, `json:"userID" db:"user_id" yaml:"uid"`},
+		{"HTMLBody", `xml:"body" json:"-"`, `json:"-" db:"html_body" xml:"body" yaml:"html_body"`},
+		{"Empty", `json:""`, `json:"" db:"empty" yaml:"empty"`},
+	}
+
+	for _, sample := range samples {
+		if out := naming.Apply(sample.field, sample.in); out != sample.out {
+			t.Errorf("got %s from %s: %s, expected %s", out, sample.field, sample.in, sample.out)
+		}
+	}
+}
+
+func TestGenerateTags(t *testing.T) {
+	naming := TagNaming{"json": CamelCaseNaming, "db": SnakeCaseNaming}
+	expected := []FieldTag{
+		{"UserID", `json:"userID" db:"user_id"`},
+		{"Email", `json:"mail,omitempty" db:"email"`},
+		{"Password", `json:"-" db:"password_hash"`},
+		{"CreatedAt", `json:"createdAt" db:"created_at" xml:"created"`},
+	}
+
+	tags, err := GenerateTags(reflect.TypeOf(&tagNamingUser{}), naming)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(tags, expected) {
+		t.Errorf("got\n\t%+v\nexpected\n\t%+v", tags, expected)
+	}
+
+	if _, err := GenerateTags(reflect.TypeOf(""), naming); err == nil {
+		t.Error("Expected an error generating tags for a string")
+	}
+}
@@ -3,55 +3,18 @@ package utils
 import (
 	"errors"
 	"reflect"
-	"regexp"
-	"sort"
 	"strconv"
 	"strings"
 )
 
 type TagString reflect.StructTag
 
-// Used for sorting
-type tagInfo struct {
-	name string
-	val  string
-}
-
-type byName []tagInfo
-
-func (a byName) Len() int      { return len(a) }
-func (a byName) Swap(i, j int) { a[i], a[j] = a[j], a[i] }
-func (a byName) Less(i, j int) bool {
-	// Favor json tags as they are what tags are generally used for
-	if a[i].name == "json" {
-		return true
-	}
-	if a[j].name == "json" {
-		return false
-	}
-	return a[i].name < a[j].name
-}
-
-// Initialize the sort regexp
-var tagStringRegExp = regexp.MustCompile(`(?:^| )([^:"]+):"([^"]*)"`)
-
 // Sort the tags alphabetically, except favor the json tag
 func (tag *TagString) Sort() {
-	matches := tagStringRegExp.FindAllStringSubmatch(string(*tag), -1)
-	if len(matches) > 0 {
-		var tags []tagInfo
-		for _, match := range matches {
-			tags = append(tags, tagInfo{match[1], match[2]})
-		}
-
-		sort.Sort(byName(tags))
-
-		tmpTag := ""
-		for _, tagInfo := range tags {
-			tmpTag += tagInfo.name + `:"` + tagInfo.val + `" `
-		}
-
-		*tag = TagString(tmpTag[:len(tmpTag)-1])
+	p, _ := ParseTag(*tag)
+	if len(p.Entries) > 0 {
+		p.Sort()
+		*tag = p.TagString()
 	}
 }
 
@@ -59,20 +22,21 @@ func (tag TagString) Get(key string) string {
 	return reflect.StructTag(tag).Get(key)
 }
 
-// TODO(morphar) All of the following functions can easily be made faster
-// Look at reflect's func (tag StructTag) Get(key string) string
-// It iterates instead of doing regexps
-// These are actually also wrong, as the values can't have " in them
+// Lookup returns the value of the tag identified by key and whether it exists
+// Unlike Get, it tells an empty value apart from a missing tag.
+func (tag TagString) Lookup(key string) (string, bool) {
+	return reflect.StructTag(tag).Lookup(key)
+}
 
 // Set ONLY sets the tag identified by key, if it already exists
 // Returns true if a tag was found and changed
 func (tag *TagString) Set(key string, val string) (success bool) {
-	re, err := regexp.Compile(`(^| )` + key + `:"[^"]*"`)
-	if err == nil && re.MatchString(string(*tag)) {
-		with := "${1}" + key + `:` + strconv.Quote(val)
-		newTag := re.ReplaceAllString(string(*tag), with)
-		*tag = TagString(newTag)
-		success = true
+	if !validTagKey(key) {
+		return
+	}
+	p, _ := ParseTag(*tag)
+	if success = p.Set(key, val); success {
+		*tag = p.TagString()
 	}
 	return
 }
@@ -90,16 +54,15 @@ func (tag *TagString) SetMulti(keyVals map[string]string) (success bool) {
 	return
 }
 
-// Add adds the tag to the TagString
+// Add adds the tag to the TagString, or changes it if it already exists
+// Keys that are not valid tag keys, e.g. containing spaces, are ignored
 func (tag *TagString) Add(key string, val string) {
-	re, err := regexp.Compile(`(^| )` + key + `:"[^"]*"`)
-	if err == nil && re.MatchString(string(*tag)) {
-		tag.Set(key, val)
-
-	} else {
-		newTag := strings.Join([]string{string(*tag), " ", key, `:`, strconv.Quote(val)}, "")
-		*tag = TagString(strings.Trim(newTag, " "))
+	if !validTagKey(key) {
+		return
 	}
+	p, _ := ParseTag(*tag)
+	p.Add(key, val)
+	*tag = p.TagString()
 }
 
 // AddMulti adds multiple tags to the TagString
@@ -112,10 +75,12 @@ func (tag *TagString) AddMulti(keyVals map[string]string) {
 // Remove removes tags identified by key
 // Returns an error if the key was not understood
 func (tag *TagString) Remove(key string) (err error) {
-	re, err := regexp.Compile(`(^| )` + key + `:"[^"]*"`)
-	if err == nil && re.MatchString(string(*tag)) {
-		newTag := re.ReplaceAllString(string(*tag), "")
-		*tag = TagString(newTag)
+	if !validTagKey(key) {
+		return errors.New("Invalid tag key: " + strconv.Quote(key))
+	}
+	p, _ := ParseTag(*tag)
+	if p.Remove(key) {
+		*tag = p.TagString()
 	}
 	return
 }
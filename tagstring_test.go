@@ -20,6 +20,19 @@ func TestGet(t *testing.T) {
 	}
 }
 
+func TestLookup(t *testing.T) {
+	var testTag TagString = `var1:"val1" var2:""`
+	if val, ok := testTag.Lookup("var1"); !ok || val != "val1" {
+		t.Error("Unable to Lookup var1 by tag name")
+	}
+	if val, ok := testTag.Lookup("var2"); !ok || val != "" {
+		t.Error("Unable to Lookup empty var2 by tag name")
+	}
+	if _, ok := testTag.Lookup("var3"); ok {
+		t.Error("Lookup found non-existing var3")
+	}
+}
+
 // The value should only be set, if the tag name exists
 func TestSet(t *testing.T) {
 	var testTag TagString = `var1:"val1" var2:"val2"`
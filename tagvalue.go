@@ -0,0 +1,108 @@
+package utils
+
+import "strings"
+
+// TagValue is a comma separated tag value like "name,opt1,opt2=value", as used
+// by the json, yaml, db and protobuf tags
+type TagValue string
+
+// Value returns the value of the tag identified by key as a TagValue
+// Changes can be written back with Set or Add, e.g.
+//
+//	val := tag.Value("json")
+//	val.AddOption("omitempty")
+//	tag.Set("json", string(val))
+func (tag TagString) Value(key string) TagValue {
+	return TagValue(tag.Get(key))
+}
+
+// Name returns the part before the first comma
+func (v TagValue) Name() string {
+	if i := strings.IndexByte(string(v), ','); i >= 0 {
+		return string(v[:i])
+	}
+	return string(v)
+}
+
+// Options returns the options following the name
+func (v TagValue) Options() []string {
+	i := strings.IndexByte(string(v), ',')
+	if i < 0 {
+		return nil
+	}
+	return strings.Split(string(v[i+1:]), ",")
+}
+
+// HasOption reports whether the option is set, either as "opt" or "opt=value"
+func (v TagValue) HasOption(opt string) bool {
+	_, ok := v.Option(opt)
+	return ok
+}
+
+// Option returns the value of the option "opt=value"
+// The value is empty if the option is set without one.
+func (v TagValue) Option(opt string) (val string, ok bool) {
+	for _, o := range v.Options() {
+		key, val, _ := strings.Cut(o, "=")
+		if key == opt {
+			return val, true
+		}
+	}
+	return
+}
+
+// SetName replaces the name, keeping the options
+func (v *TagValue) SetName(name string) {
+	if i := strings.IndexByte(string(*v), ','); i >= 0 {
+		*v = TagValue(name) + (*v)[i:]
+	} else {
+		*v = TagValue(name)
+	}
+}
+
+// AddOption adds an option without a value, e.g. "omitempty"
+// An existing option with the same name is replaced.
+func (v *TagValue) AddOption(opt string) {
+	v.setOption(opt, opt)
+}
+
+// SetOption adds the option "opt=val"
+// An existing option with the same name is replaced.
+func (v *TagValue) SetOption(opt, val string) {
+	v.setOption(opt, opt+"="+val)
+}
+
+func (v *TagValue) setOption(opt, option string) {
+	opts := v.Options()
+	for i, o := range opts {
+		if key, _, _ := strings.Cut(o, "="); key == opt {
+			opts[i] = option
+			v.setOptions(opts)
+			return
+		}
+	}
+	v.setOptions(append(opts, option))
+}
+
+// RemoveOption removes the option, with or without a value
+// Returns true if the option was found
+func (v *TagValue) RemoveOption(opt string) (success bool) {
+	opts := v.Options()
+	n := 0
+	for _, o := range opts {
+		if key, _, _ := strings.Cut(o, "="); key == opt {
+			success = true
+			continue
+		}
+		opts[n] = o
+		n++
+	}
+	if success {
+		v.setOptions(opts[:n])
+	}
+	return
+}
+
+func (v *TagValue) setOptions(opts []string) {
+	*v = TagValue(strings.Join(append([]string{v.Name()}, opts...), ","))
+}
@@ -0,0 +1,83 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTagValue(t *testing.T) {
+	var testTag TagString = `json:"id,omitempty,string" db:"user_id,default=0,pk" yaml:",inline" xml:"name"`
+
+	val := testTag.Value("json")
+	if val.Name() != "id" {
+		t.Errorf("got name %q, expected %q", val.Name(), "id")
+	}
+	if !reflect.DeepEqual(val.Options(), []string{"omitempty", "string"}) {
+		t.Errorf("got options %q", val.Options())
+	}
+	if !val.HasOption("omitempty") || val.HasOption("omit") || val.HasOption("id") {
+		t.Error("HasOption doesn't work on json")
+	}
+
+	val = testTag.Value("db")
+	if opt, ok := val.Option("default"); !ok || opt != "0" {
+		t.Errorf("got %q, %v for option default, expected %q", opt, ok, "0")
+	}
+	if opt, ok := val.Option("pk"); !ok || opt != "" {
+		t.Errorf("got %q, %v for option pk", opt, ok)
+	}
+	if !val.HasOption("default") {
+		t.Error("HasOption doesn't find options with values")
+	}
+
+	val = testTag.Value("yaml")
+	if val.Name() != "" || !val.HasOption("inline") {
+		t.Errorf("got name %q and options %q from yaml", val.Name(), val.Options())
+	}
+
+	val = testTag.Value("xml")
+	if val.Options() != nil || val.HasOption("name") {
+		t.Errorf("got options %q from xml, expected none", val.Options())
+	}
+
+	if testTag.Value("missing") != "" {
+		t.Error("Got a value for a missing tag")
+	}
+}
+
+func TestTagValueEdit(t *testing.T) {
+	samples := []struct {
+		in   TagValue
+		edit func(v *TagValue)
+		out  TagValue
+	}{
+		{"id", func(v *TagValue) { v.AddOption("omitempty") }, "id,omitempty"},
+		{"id,omitempty", func(v *TagValue) { v.AddOption("omitempty") }, "id,omitempty"},
+		{"", func(v *TagValue) { v.AddOption("omitempty") }, ",omitempty"},
+		{"id,default=1,pk", func(v *TagValue) { v.SetOption("default", "2") }, "id,default=2,pk"},
+		{"id,pk", func(v *TagValue) { v.SetOption("default", "2") }, "id,pk,default=2"},
+		{"id,default=1,pk", func(v *TagValue) { v.RemoveOption("default") }, "id,pk"},
+		{"id,pk", func(v *TagValue) { v.RemoveOption("pk") }, "id"},
+		{"id,pk", func(v *TagValue) { v.RemoveOption("missing") }, "id,pk"},
+		{"id,omitempty", func(v *TagValue) { v.SetName("user_id") }, "user_id,omitempty"},
+		{"id", func(v *TagValue) { v.SetName("-") }, "-"},
+	}
+
+	for _, sample := range samples {
+		val := sample.in
+		sample.edit(&val)
+		if val != sample.out {
+			t.Errorf("got %q from %q, expected %q", val, sample.in, sample.out)
+		}
+	}
+}
+
+func TestTagValueWriteBack(t *testing.T) {
+	var testTag TagString = `json:"id" db:"id"`
+	val := testTag.Value("json")
+	val.AddOption("omitempty")
+	testTag.Set("json", string(val))
+	if testTag != `json:"id,omitempty" db:"id"` {
+		t.Errorf("got %s", testTag)
+	}
+}
@@ -0,0 +1,166 @@
+package utils
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// transliterations maps Latin-extended, Greek and Cyrillic letters to ASCII
+// The Latin letters are those of Latin-1 Supplement, Latin Extended-A/B and
+// Latin Extended Additional.
+var transliterations = map[rune]string{}
+
+func init() {
+	// Groups of letters sharing the same ASCII replacement
+	groups := []struct {
+		from, to string
+	}{
+		// Latin-1 Supplement and Latin Extended-A/B
+		{"ÀÁÂÃÅĀĂĄǍǞǠǺȀȂȦ", "A"}, {"àáâãåāăąǎǟǡǻȁȃȧ", "a"},
+		{"ÄÆǢǼ", "Ae"}, {"äæǣǽ", "ae"},
+		{"ÇĆĈĊČ", "C"}, {"çćĉċč", "c"},
+		{"ÐĎĐ", "D"}, {"ðďđ", "d"},
+		{"ÈÉÊËĒĔĖĘĚȄȆȨ", "E"}, {"èéêëēĕėęěȅȇȩ", "e"},
+		{"ĜĞĠĢǤǦǴ", "G"}, {"ĝğġģǥǧǵ", "g"},
+		{"ĤĦȞ", "H"}, {"ĥħȟ", "h"},
+		{"ÌÍÎÏĨĪĬĮİǏȈȊ", "I"}, {"ìíîïĩīĭįıǐȉȋ", "i"},
+		{"Ĳ", "IJ"}, {"ĳ", "ij"},
+		{"Ĵ", "J"}, {"ĵǰ", "j"},
+		{"ĶǨ", "K"}, {"ķĸǩ", "k"},
+		{"ĹĻĽĿŁ", "L"}, {"ĺļľŀł", "l"},
+		{"ÑŃŅŇŊǸ", "N"}, {"ñńņňŉŋǹ", "n"},
+		{"ÒÓÔÕØŌŎŐƠǑǪǬǾȌȎȮȰ", "O"}, {"òóôõøōŏőơǒǫǭǿȍȏȯȱ", "o"},
+		{"ÖŒ", "Oe"}, {"öœ", "oe"},
+		{"ŔŖŘȐȒ", "R"}, {"ŕŗřȑȓ", "r"},
+		{"ŚŜŞŠȘ", "S"}, {"śŝşšșſ", "s"},
+		{"ß", "ss"}, {"ẞ", "Ss"},
+		{"ŢŤŦȚ", "T"}, {"ţťŧț", "t"},
+		{"Þ", "Th"}, {"þ", "th"},
+		{"ÙÚÛŨŪŬŮŰŲƯǓǕǗǙǛȔȖ", "U"}, {"ùúûũūŭůűųưǔǖǘǚǜȕȗ", "u"},
+		{"Ü", "Ue"}, {"ü", "ue"},
+		{"Ŵ", "W"}, {"ŵ", "w"},
+		{"ÝŶŸȲ", "Y"}, {"ýÿŷȳ", "y"},
+		{"ŹŻŽ", "Z"}, {"źżž", "z"},
+		{"Ǆ", "DZ"}, {"ǅ", "Dz"}, {"ǆ", "dz"},
+		{"Ǳ", "DZ"}, {"ǲ", "Dz"}, {"ǳ", "dz"},
+		{"Ǉ", "LJ"}, {"ǈ", "Lj"}, {"ǉ", "lj"},
+		{"Ǌ", "NJ"}, {"ǋ", "Nj"}, {"ǌ", "nj"},
+
+		// Latin Extended Additional, e.g. Vietnamese
+		{"ḀẠẢẤẦẨẪẬẮẰẲẴẶ", "A"}, {"ḁạảấầẩẫậắằẳẵặẚ", "a"},
+		{"ḂḄḆ", "B"}, {"ḃḅḇ", "b"},
+		{"Ḉ", "C"}, {"ḉ", "c"},
+		{"ḊḌḎḐḒ", "D"}, {"ḋḍḏḑḓẟ", "d"},
+		{"ḔḖḘḚḜẸẺẼẾỀỂỄỆ", "E"}, {"ḕḗḙḛḝẹẻẽếềểễệ", "e"},
+		{"Ḟ", "F"}, {"ḟ", "f"},
+		{"Ḡ", "G"}, {"ḡ", "g"},
+		{"ḢḤḦḨḪ", "H"}, {"ḣḥḧḩḫẖ", "h"},
+		{"ḬḮỈỊ", "I"}, {"ḭḯỉị", "i"},
+		{"ḰḲḴ", "K"}, {"ḱḳḵ", "k"},
+		{"ḶḸḺḼ", "L"}, {"ḷḹḻḽ", "l"},
+		{"Ỻ", "LL"}, {"ỻ", "ll"},
+		{"ḾṀṂ", "M"}, {"ḿṁṃ", "m"},
+		{"ṄṆṈṊ", "N"}, {"ṅṇṉṋ", "n"},
+		{"ṌṎṐṒỌỎỐỒỔỖỘỚỜỞỠỢ", "O"}, {"ṍṏṑṓọỏốồổỗộớờởỡợ", "o"},
+		{"ṔṖ", "P"}, {"ṕṗ", "p"},
+		{"ṘṚṜṞ", "R"}, {"ṙṛṝṟ", "r"},
+		{"ṠṢṤṦṨ", "S"}, {"ṡṣṥṧṩẛẜẝ", "s"},
+		{"ṪṬṮṰ", "T"}, {"ṫṭṯṱẗ", "t"},
+		{"ṲṴṶṸṺỤỦỨỪỬỮỰ", "U"}, {"ṳṵṷṹṻụủứừửữự", "u"},
+		{"ṼṾỼ", "V"}, {"ṽṿỽ", "v"},
+		{"ẀẂẄẆẈ", "W"}, {"ẁẃẅẇẉẘ", "w"},
+		{"ẊẌ", "X"}, {"ẋẍ", "x"},
+		{"ẎỲỴỶỸỾ", "Y"}, {"ẏẙỳỵỷỹỿ", "y"},
+		{"ẐẒẔ", "Z"}, {"ẑẓẕ", "z"},
+
+		// Greek
+		{"ΑΆ", "A"}, {"αά", "a"},
+		{"Β", "V"}, {"β", "v"},
+		{"Γ", "G"}, {"γ", "g"},
+		{"Δ", "D"}, {"δ", "d"},
+		{"ΕΈ", "E"}, {"εέ", "e"},
+		{"Ζ", "Z"}, {"ζ", "z"},
+		{"ΗΉΙΊΪ", "I"}, {"ηήιίϊΐ", "i"},
+		{"Θ", "Th"}, {"θ", "th"},
+		{"Κ", "K"}, {"κ", "k"},
+		{"Λ", "L"}, {"λ", "l"},
+		{"Μ", "M"}, {"μ", "m"},
+		{"Ν", "N"}, {"ν", "n"},
+		{"Ξ", "X"}, {"ξ", "x"},
+		{"ΟΌΩΏ", "O"}, {"οόωώ", "o"},
+		{"Π", "P"}, {"π", "p"},
+		{"Ρ", "R"}, {"ρ", "r"},
+		{"Σ", "S"}, {"σς", "s"},
+		{"Τ", "T"}, {"τ", "t"},
+		{"ΥΎΫ", "Y"}, {"υύϋΰ", "y"},
+		{"Φ", "F"}, {"φ", "f"},
+		{"Χ", "Ch"}, {"χ", "ch"},
+		{"Ψ", "Ps"}, {"ψ", "ps"},
+
+		// Cyrillic
+		{"А", "A"}, {"а", "a"},
+		{"Б", "B"}, {"б", "b"},
+		{"В", "V"}, {"в", "v"},
+		{"ГҐ", "G"}, {"гґ", "g"},
+		{"Д", "D"}, {"д", "d"},
+		{"ЕЭ", "E"}, {"еэ", "e"},
+		{"Ё", "Yo"}, {"ё", "yo"},
+		{"Є", "Ye"}, {"є", "ye"},
+		{"Ж", "Zh"}, {"ж", "zh"},
+		{"З", "Z"}, {"з", "z"},
+		{"ИІ", "I"}, {"иі", "i"},
+		{"Ї", "Yi"}, {"ї", "yi"},
+		{"ЙЫ", "Y"}, {"йы", "y"},
+		{"Ј", "J"}, {"ј", "j"},
+		{"К", "K"}, {"к", "k"},
+		{"Л", "L"}, {"л", "l"},
+		{"Љ", "Lj"}, {"љ", "lj"},
+		{"М", "M"}, {"м", "m"},
+		{"Н", "N"}, {"н", "n"},
+		{"Њ", "Nj"}, {"њ", "nj"},
+		{"О", "O"}, {"о", "o"},
+		{"П", "P"}, {"п", "p"},
+		{"Р", "R"}, {"р", "r"},
+		{"С", "S"}, {"с", "s"},
+		{"Т", "T"}, {"т", "t"},
+		{"Ћ", "C"}, {"ћ", "c"},
+		{"Ђ", "Dj"}, {"ђ", "dj"},
+		{"У", "U"}, {"у", "u"},
+		{"Ф", "F"}, {"ф", "f"},
+		{"Х", "Kh"}, {"х", "kh"},
+		{"Ц", "Ts"}, {"ц", "ts"},
+		{"Ч", "Ch"}, {"ч", "ch"},
+		{"Џ", "Dz"}, {"џ", "dz"},
+		{"Ш", "Sh"}, {"ш", "sh"},
+		{"Щ", "Shch"}, {"щ", "shch"},
+		{"ЪЬ", ""}, {"ъь", ""},
+		{"Ю", "Yu"}, {"ю", "yu"},
+		{"Я", "Ya"}, {"я", "ya"},
+	}
+
+	for _, group := range groups {
+		for _, r := range group.from {
+			transliterations[r] = group.to
+		}
+	}
+}
+
+// Transliterate replaces Latin-extended, Greek and Cyrillic letters with their
+// closest ASCII equivalent, e.g. "Ærø Café" becomes "Aero Cafe"
+// Combining marks are removed and all other runes are left untouched.
+func Transliterate(str string) string {
+	var buf strings.Builder
+	buf.Grow(len(str))
+
+	for _, r := range str {
+		if r < utf8.RuneSelf {
+			buf.WriteRune(r)
+		} else if to, ok := transliterations[r]; ok {
+			buf.WriteString(to)
+		} else if !unicode.IsMark(r) {
+			buf.WriteRune(r)
+		}
+	}
+	return buf.String()
+}
@@ -0,0 +1,36 @@
+package utils
+
+import (
+	"testing"
+	"unicode"
+)
+
+func TestTransliterate(t *testing.T) {
+	samples := []sample{
+		{"Ærø Café", "Aero Cafe"},
+		{"Smørrebrød på Ærø", "Smorrebrod pa Aero"},
+		{"Größe", "Groesse"},
+		{"Łódź", "Lodz"},
+		{"Hà Nội", "Ha Noi"},
+		{"ǅemal Ǉubović", "Dzemal LJubovic"},
+		{"Ελληνικά", "Ellinika"},
+		{"Щука и Жук", "Shchuka i Zhuk"},
+		{"Café", "Cafe"},
+		{"日本語", "日本語"},
+		{"plain ascii", "plain ascii"},
+	}
+
+	for _, sample := range samples {
+		if out := Transliterate(sample.str); out != sample.out {
+			t.Errorf("got %q from %q, expected %q", out, sample.str, sample.out)
+		}
+	}
+}
+
+func TestTransliterateLatinExtendedAdditional(t *testing.T) {
+	for r := rune(0x1E00); r <= 0x1EFF; r++ {
+		if _, ok := transliterations[r]; unicode.IsLetter(r) && !ok {
+			t.Errorf("%q (%U) is not transliterated", r, r)
+		}
+	}
+}
@@ -0,0 +1,187 @@
+package utils
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// The problems reported by go vet's structtag check
+const (
+	errTagSyntax      = "bad syntax for struct tag pair"
+	errTagKeySyntax   = "bad syntax for struct tag key"
+	errTagValueSyntax = "bad syntax for struct tag value"
+	errTagValueSpace  = "suspicious space in struct tag value"
+	errTagSpace       = "key:\"value\" pairs not separated by spaces"
+	errTagDuplicate   = "duplicate key"
+)
+
+// TagError is a problem found in a struct tag
+type TagError struct {
+	Field   string // Go field name, set by ValidateStruct
+	Offset  int    // Byte offset into the tag
+	Key     string // Key of the tag entry, if known
+	Problem string
+}
+
+func (e *TagError) Error() string {
+	var parts []string
+	if e.Field != "" {
+		parts = append(parts, "field "+e.Field)
+	}
+	parts = append(parts, "offset "+strconv.Itoa(e.Offset))
+	if e.Key != "" {
+		parts = append(parts, "key "+e.Key)
+	}
+	return strings.Join(append(parts, e.Problem), ": ")
+}
+
+// Validate checks the tag like go vet does and returns all problems found
+// Besides syntax errors, keys used more than once and suspicious spaces in
+// json, xml and asn1 values are reported. Nothing after a syntax error can be
+// checked.
+func (tag TagString) Validate() (errs []*TagError) {
+	p, err := ParseTag(tag)
+
+	seen := map[string]bool{}
+	offset := 0
+	for i, e := range p.Entries {
+		offset += len(e.Space)
+		if i > 0 && e.Space == "" {
+			errs = append(errs, &TagError{Offset: offset, Key: e.Key, Problem: errTagSpace})
+		}
+		if seen[e.Key] {
+			errs = append(errs, &TagError{Offset: offset, Key: e.Key, Problem: errTagDuplicate})
+		}
+		seen[e.Key] = true
+
+		valOffset := offset + len(e.Key) + 1
+		if val, err := e.Value(); err != nil {
+			errs = append(errs, &TagError{Offset: valOffset, Key: e.Key, Problem: errTagValueSyntax})
+		} else if suspiciousSpace(e.Key, val) {
+			errs = append(errs, &TagError{Offset: valOffset, Key: e.Key, Problem: errTagValueSpace})
+		}
+		offset = valOffset + len(e.Raw)
+	}
+
+	if err != nil {
+		errs = append(errs, err.(*TagError))
+	}
+	return
+}
+
+// suspiciousSpace reports spaces in values the way go vet does
+func suspiciousSpace(key, val string) bool {
+	switch key {
+	case "xml":
+		// Leading, trailing or multiple spaces and a space before a comma
+		if strings.Trim(val, " ") != val || strings.Count(val, " ") > 1 {
+			return true
+		}
+		comma := strings.IndexByte(val, ',')
+		if comma < 0 {
+			return false
+		}
+		if comma > 0 && val[comma-1] == ' ' {
+			return true
+		}
+		val = val[comma+1:]
+	case "json":
+		// JSON allows spaces in the name
+		comma := strings.IndexByte(val, ',')
+		if comma < 0 {
+			return false
+		}
+		val = val[comma+1:]
+	case "asn1":
+	default:
+		return false
+	}
+	return strings.IndexByte(val, ' ') >= 0
+}
+
+// ValidateStruct validates the tags of all fields of typ, which must be a
+// struct or a pointer to one
+// It also reports fields of the struct that use the same name for one of the
+// given tag keys, e.g. two fields both tagged json:"id". Without keys, json
+// and xml names are checked, like go vet does. Names of embedded structs
+// without a name of their own are checked along with the outer struct.
+func ValidateStruct(typ reflect.Type, keys ...string) (errs []*TagError, err error) {
+	for typ != nil && typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ == nil || typ.Kind() != reflect.Struct {
+		return nil, errors.New("Not a struct")
+	}
+	if len(keys) == 0 {
+		keys = []string{"json", "xml"}
+	}
+
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		for _, tagErr := range TagString(field.Tag).Validate() {
+			tagErr.Field = field.Name
+			errs = append(errs, tagErr)
+		}
+	}
+
+	for _, key := range keys {
+		names := map[string]string{}
+		errs = append(errs, duplicateNames(typ, key, "", names, map[reflect.Type]bool{typ: true})...)
+	}
+	return
+}
+
+// duplicateNames reports fields of typ using a name for key that is already
+// used by another field
+// names maps the names seen so far to the path of the field using it.
+func duplicateNames(typ reflect.Type, key, prefix string, names map[string]string, visited map[reflect.Type]bool) (errs []*TagError) {
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		tag := TagString(field.Tag)
+		name := tag.Value(key).Name()
+
+		if name == "" && field.Anonymous {
+			ft := field.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct && !visited[ft] {
+				visited[ft] = true
+				errs = append(errs, duplicateNames(ft, key, prefix+field.Name+".", names, visited)...)
+			}
+			continue
+		}
+		if name == "" || name == "-" {
+			continue
+		}
+
+		path := prefix + field.Name
+		if other, ok := names[name]; ok {
+			errs = append(errs, &TagError{
+				Field:   path,
+				Offset:  tagValueOffset(tag, key),
+				Key:     key,
+				Problem: "repeats " + key + " name " + strconv.Quote(name) + " also used by field " + other,
+			})
+			continue
+		}
+		names[name] = path
+	}
+	return
+}
+
+// tagValueOffset returns the offset of the quoted value of key in tag
+func tagValueOffset(tag TagString, key string) int {
+	p, _ := ParseTag(tag)
+	offset := 0
+	for _, e := range p.Entries {
+		offset += len(e.Space)
+		if e.Key == key {
+			return offset + len(e.Key) + 1
+		}
+		offset += len(e.Key) + 1 + len(e.Raw)
+	}
+	return 0
+}
@@ -0,0 +1,107 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	var tests = []struct {
+		in   TagString
+		errs []TagError
+	}{
+		{`json:"id,omitempty" db:"id"`, nil},
+		{``, nil},
+		{`json:"id" json:"user_id"`, []TagError{{Offset: 10, Key: "json", Problem: errTagDuplicate}}},
+		{`json:"id"db:"id"`, []TagError{{Offset: 9, Key: "db", Problem: errTagSpace}}},
+		{`json:"id" db:id`, []TagError{{Offset: 13, Key: "db", Problem: errTagValueSyntax}}},
+		{`json:"id" db:"id`, []TagError{{Offset: 13, Key: "db", Problem: errTagValueSyntax}}},
+		{`json:"id" db "id"`, []TagError{{Offset: 10, Key: "db", Problem: errTagSyntax}}},
+		{`json:"id" :"id"`, []TagError{{Offset: 10, Problem: errTagKeySyntax}}},
+		{`json:"\q"`, []TagError{{Offset: 5, Key: "json", Problem: errTagValueSyntax}}},
+		{`json:"user id"`, nil},
+		{`json:"id, omitempty"`, []TagError{{Offset: 5, Key: "json", Problem: errTagValueSpace}}},
+		{`xml:" id"`, []TagError{{Offset: 4, Key: "xml", Problem: errTagValueSpace}}},
+		{`xml:"a b,attr"`, nil},
+		{`xml:"a ,attr"`, []TagError{{Offset: 4, Key: "xml", Problem: errTagValueSpace}}},
+		{`validate:"min=1, max=2"`, nil},
+		{`a:"1"a:"2" b`, []TagError{
+			{Offset: 5, Key: "a", Problem: errTagSpace},
+			{Offset: 5, Key: "a", Problem: errTagDuplicate},
+			{Offset: 11, Key: "b", Problem: errTagSyntax},
+		}},
+	}
+
+	for _, tt := range tests {
+		var errs []TagError
+		for _, err := range tt.in.Validate() {
+			errs = append(errs, *err)
+		}
+		if !reflect.DeepEqual(errs, tt.errs) {
+			t.Errorf("got %+v validating %s, expected %+v", errs, tt.in, tt.errs)
+		}
+	}
+}
+
+func TestParseTagError(t *testing.T) {
+	_, err := ParseTag(`json:"id" db:id`)
+	tagErr, ok := err.(*TagError)
+	if !ok {
+		t.Fatalf("got %T, expected *TagError", err)
+	}
+	if tagErr.Error() != "offset 13: key db: "+errTagValueSyntax {
+		t.Errorf("got %q", tagErr.Error())
+	}
+}
+
+type validateBase struct {
+	ID int `json:"id"`
+}
+
+type validateUser struct {
+	validateBase
+	UserID  int    `json:"id"`
+	Email   string `json:"email"`
+	Ignored string `json:"-"`
+	Other   string `json:"-"`
+}
+
+func TestValidateStruct(t *testing.T) {
+	errs, err := ValidateStruct(reflect.TypeOf(&validateUser{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `field UserID: offset 5: key json: repeats json name "id" also used by field validateBase.ID`
+	if len(errs) != 1 || errs[0].Error() != expected {
+		t.Errorf("got %v, expected %q", errs, expected)
+	}
+
+	// Built at runtime, as go vet rightly refuses these tags
+	typ := reflect.StructOf([]reflect.StructField{
+		{Name: "A", Type: reflect.TypeOf(""), Tag: `json:"a" xml:"id"`},
+		{Name: "B", Type: reflect.TypeOf(""), Tag: `json:"b" xml:"id"`},
+		{Name: "Broken", Type: reflect.TypeOf(""), Tag: `json:"broken"db:"a"`},
+	})
+	errs, _ = ValidateStruct(typ)
+	expectedErrs := []string{
+		"field Broken: offset 13: key db: " + errTagSpace,
+		`field B: offset 13: key xml: repeats xml name "id" also used by field A`,
+	}
+	if len(errs) != len(expectedErrs) {
+		t.Fatalf("got %d errors, expected %d: %v", len(errs), len(expectedErrs), errs)
+	}
+	for i, err := range errs {
+		if err.Error() != expectedErrs[i] {
+			t.Errorf("got %q, expected %q", err.Error(), expectedErrs[i])
+		}
+	}
+
+	errs, _ = ValidateStruct(typ, "db")
+	if len(errs) != 1 {
+		t.Errorf("got %v checking db names, expected only the syntax error", errs)
+	}
+
+	if _, err := ValidateStruct(reflect.TypeOf(1)); err == nil {
+		t.Error("Expected an error validating an int")
+	}
+}